@@ -16,6 +16,7 @@ import (
 	"go-core-api/pkg/database"
 	"go-core-api/pkg/logger"
 	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/sms"
 	"go-core-api/pkg/utils"
 
 	"go.uber.org/zap"
@@ -31,13 +32,18 @@ func main() {
 	cfg := config.AppConfig
 
 	database.ConnectDB(cfg.Database.DSN)
-	database.DB.AutoMigrate(&models.User{})
+	database.DB.AutoMigrate(&models.User{}, &models.Session{}, &models.RecoveryCode{}, &models.PersonalAccessToken{}, &models.LoginThrottle{}, &models.PasswordHistory{}, &models.Identity{}, &models.OAuthClient{}, &models.OAuthAuthorizationCode{}, &models.Role{}, &models.Permission{}, &models.Organization{}, &models.Membership{}, &models.Invitation{}, &models.ImpersonationLog{}, &models.LoginAttempt{})
 
 	mailService := mailer.NewMailer(
 		cfg.Mailer.Host, cfg.Mailer.Port,
 		cfg.Mailer.User, cfg.Mailer.Password, cfg.Mailer.From,
 	)
 
+	smsSender, err := sms.New(cfg.SMS)
+	if err != nil {
+		logger.Fatal("Cấu hình SMS không hợp lệ", zap.Error(err))
+	}
+
 	// 2. (BACKGROUND WORKERS)
 	ctxWorker, cancelWorker := context.WithCancel(context.Background())
 	defer cancelWorker()
@@ -46,7 +52,7 @@ func main() {
 	go middlewares.InitRateLimiterCleanup(ctxWorker)
 
 	// 3. DEPENDENCY INJECTION & ROUTER
-	r := server.SetupDependenciesAndRouter(database.DB, cfg, mailService)
+	r := server.SetupDependenciesAndRouter(database.DB, cfg, mailService, smsSender)
 
 	// 4. SERVER & GRACEFUL SHUTDOWN
 	port := fmt.Sprintf(":%d", cfg.Server.Port)
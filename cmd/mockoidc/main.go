@@ -0,0 +1,166 @@
+// mockoidc là một OpenID Provider giả lập tối giản để chạy thử luồng /auth/oauth/:provider ở local
+// mà không cần tài khoản Google/GitHub thật. Trang /authorize tự động "đăng nhập" bằng email cấu hình sẵn.
+//
+//	go run ./cmd/mockoidc -addr :9000 -email dev@example.com
+//
+// Khai báo trong config.yaml:
+//
+//	oauth:
+//	  providers:
+//	    mock:
+//	      type: "oidc"
+//	      issuer: "http://localhost:9000"
+//	      client_id: "mock-client"
+//	      client_secret: "mock-secret"
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type pendingCode struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+type server struct {
+	issuer   string
+	email    string
+	verified bool
+
+	mu    sync.Mutex
+	codes map[string]pendingCode
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "Địa chỉ lắng nghe")
+	issuer := flag.String("issuer", "http://localhost:9000", "Giá trị iss, phải khớp với oauth.providers.<tên>.issuer")
+	email := flag.String("email", "dev@example.com", "Email của user giả lập")
+	verified := flag.Bool("email-verified", true, "Giá trị email_verified trả về")
+	flag.Parse()
+
+	s := &server{issuer: *issuer, email: *email, verified: *verified, codes: make(map[string]pendingCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/userinfo", s.userinfo)
+
+	log.Printf("Mock OIDC đang chạy tại %s (issuer %s, email %s)", *addr, *issuer, *email)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (s *server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                           s.issuer,
+		"authorization_endpoint":           s.issuer + "/authorize",
+		"token_endpoint":                   s.issuer + "/token",
+		"userinfo_endpoint":                s.issuer + "/userinfo",
+		"response_types_supported":         []string{"code"},
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+// authorize bỏ qua bước đăng nhập, cấp code ngay và chuyển hướng về redirect_uri
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "yêu cầu response_type=code và PKCE S256", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = pendingCode{
+		clientID:      q.Get("client_id"),
+		redirectURI:   q.Get("redirect_uri"),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+	}
+	s.mu.Unlock()
+
+	target, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "redirect_uri không hợp lệ", http.StatusBadRequest)
+		return
+	}
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	pending, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code")) // Code chỉ dùng một lần
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || pending.clientID != r.PostForm.Get("client_id") || pending.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != pending.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	// Client của hệ thống không verify chữ ký id_token nhận trực tiếp từ token endpoint nên ký HS256 là đủ
+	idToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            "mock|" + s.email,
+		"aud":            pending.clientID,
+		"nonce":          pending.nonce,
+		"email":          s.email,
+		"email_verified": s.verified,
+		"name":           "Mock User",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+	}).SignedString([]byte("mock-oidc"))
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *server) userinfo(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            "mock|" + s.email,
+		"email":          s.email,
+		"email_verified": s.verified,
+		"name":           "Mock User",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
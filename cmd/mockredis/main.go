@@ -0,0 +1,216 @@
+// mockredis là server giả lập Redis tối giản (giao thức RESP, dữ liệu chỉ nằm trong RAM) để chạy thử
+// cache driver redis ở local mà không cần cài Redis thật. Hỗ trợ PING, AUTH, SELECT, GET, SET (EX/PX), DEL, FLUSHALL.
+//
+//	go run ./cmd/mockredis -addr :6380
+//
+// Khai báo trong config.yaml:
+//
+//	cache:
+//	  driver: "redis"
+//	  redis:
+//	    addr: "localhost:6380"
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value     string
+	expiresAt time.Time // Zero = không hết hạn
+}
+
+type server struct {
+	password string
+
+	mu   sync.Mutex
+	data map[string]entry
+}
+
+func main() {
+	addr := flag.String("addr", ":6380", "Địa chỉ lắng nghe")
+	password := flag.String("password", "", "Mật khẩu yêu cầu qua AUTH (bỏ trống = không cần)")
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := &server{password: *password, data: make(map[string]entry)}
+	log.Printf("Mock Redis đang chạy tại %s", *addr)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("Lỗi nhận kết nối: %v", err)
+			continue
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	authed := s.password == ""
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Printf("Lỗi đọc lệnh: %v", err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "AUTH":
+			authed = len(args) == 2 && args[1] == s.password
+			if !authed {
+				writeError(writer, "WRONGPASS invalid password")
+			} else {
+				writer.WriteString("+OK\r\n")
+			}
+		case !authed:
+			writeError(writer, "NOAUTH Authentication required")
+		case name == "QUIT":
+			writer.WriteString("+OK\r\n")
+			writer.Flush()
+			return
+		default:
+			s.execute(writer, name, args[1:])
+		}
+
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *server) execute(w *bufio.Writer, name string, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "SELECT":
+		w.WriteString("+OK\r\n") // Mọi database dùng chung một không gian key
+	case "FLUSHALL", "FLUSHDB":
+		s.data = make(map[string]entry)
+		w.WriteString("+OK\r\n")
+	case "GET":
+		if len(args) != 1 {
+			writeError(w, "ERR wrong number of arguments for 'get' command")
+			return
+		}
+		e, ok := s.lookup(args[0])
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(e.value), e.value)
+	case "SET":
+		if len(args) < 2 {
+			writeError(w, "ERR wrong number of arguments for 'set' command")
+			return
+		}
+		e := entry{value: args[1]}
+		for i := 2; i+1 < len(args); i += 2 {
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			switch strings.ToUpper(args[i]) {
+			case "EX":
+				e.expiresAt = time.Now().Add(time.Duration(n) * time.Second)
+			case "PX":
+				e.expiresAt = time.Now().Add(time.Duration(n) * time.Millisecond)
+			default:
+				writeError(w, "ERR syntax error")
+				return
+			}
+		}
+		s.data[args[0]] = e
+		w.WriteString("+OK\r\n")
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.lookup(key); ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", name))
+	}
+}
+
+// lookup trả về entry còn hạn, entry đã hết hạn bị xoá luôn (phải giữ s.mu)
+func (s *server) lookup(key string) (entry, bool) {
+	e, ok := s.data[key]
+	if ok && !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		delete(s.data, key)
+		return entry{}, false
+	}
+	return e, ok
+}
+
+// readCommand đọc một lệnh dạng mảng bulk string (*<n>\r\n$<len>\r\n<arg>\r\n...)
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil // Lệnh inline, VD: gõ tay qua telnet
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("độ dài mảng không hợp lệ: %q", line)
+	}
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		header = strings.TrimRight(header, "\r\n")
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("mong đợi bulk string, nhận %q", header)
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("độ dài bulk string không hợp lệ: %q", header)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:size]))
+	}
+	return args, nil
+}
+
+func writeError(w *bufio.Writer, message string) {
+	w.WriteString("-" + message + "\r\n")
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required"`
+}
+
+// POST /api/v1/users/me/email
+// Gửi link xác nhận tới email mới, email đăng nhập chưa đổi cho tới khi link được bấm
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	client := clientInfoFromRequest(c, "")
+	if err := h.service.RequestEmailChange(c.Request.Context(), userID, req.Password, req.NewEmail, client); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Vui lòng kiểm tra hộp thư của email mới để xác nhận thay đổi", nil)
+}
+
+// POST /api/v1/auth/email-change/confirm
+// Trang Frontend gửi token lấy từ link gửi tới email mới. Không nhận GET để trình quét link
+// của hòm thư không tự đổi email thay người dùng
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.ConfirmEmailChange(c.Request.Context(), req.Token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đổi email thành công, vui lòng đăng nhập lại bằng email mới", nil)
+}
+
+// POST /api/v1/auth/email-change/revert
+// Trang Frontend gửi token lấy từ link gửi tới email cũ: huỷ hoặc hoàn tác việc đổi email
+func (h *AuthHandler) RevertEmailChange(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.RevertEmailChange(c.Request.Context(), req.Token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã giữ lại email cũ và đăng xuất mọi thiết bị. Hãy đặt lại mật khẩu nếu nghi ngờ tài khoản bị lộ", nil)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"go-core-api/internal/services"
 	"go-core-api/pkg/custom_error"
@@ -21,22 +22,46 @@ func NewAuthHandler(service services.AuthService) *AuthHandler {
 	}
 }
 
+// ForgotPasswordRequest nhận email hoặc số điện thoại đã xác thực (nhận mã qua SMS)
 type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required_without=Phone,omitempty,email"`
+	Phone string `json:"phone" binding:"max=32"`
+}
+
+type ResendVerificationRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
 
 type ResetPasswordRequest struct {
+	Email       string `json:"email" binding:"required_without=Phone,omitempty,email"`
+	Phone       string `json:"phone" binding:"max=32"`
 	OTP         string `json:"otp" binding:"required,len=6"`
-	NewPassword string `json:"new_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required,max=128"` // Độ mạnh do PasswordPolicy kiểm tra
 }
 
 type AuthRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required,max=128"` // Độ mạnh do PasswordPolicy kiểm tra
+}
+
+type LoginRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"` // Không áp chính sách mới để tài khoản cũ vẫn đăng nhập được
+	DeviceName string `json:"device_name" binding:"max=100"`
+}
+
+type Login2FARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+	DeviceName     string `json:"device_name" binding:"max=100"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
 }
 
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token"` // Bỏ trống khi dùng chế độ cookie
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -57,25 +82,84 @@ func (h *AuthHandler) Register(c *gin.Context) {
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
-	var req AuthRequest
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	client := clientInfoFromRequest(c, req.DeviceName)
+	tokens, challenge, err := h.service.Login(c.Request.Context(), req.Email, req.Password, client)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if challenge != nil {
+		response.Success(c, http.StatusOK, "Vui lòng nhập mã xác thực 2 lớp", challenge)
+		return
+	}
+
+	respondWithTokens(c, "Đăng nhập thành công", tokens)
+}
+
+// Login2FA hoàn tất đăng nhập bằng challenge token + mã TOTP/recovery code
+func (h *AuthHandler) Login2FA(c *gin.Context) {
+	var req Login2FARequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, custom_error.ErrInvalidRequest)
 		return
 	}
 
-	tokens, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
+	client := clientInfoFromRequest(c, req.DeviceName)
+	tokens, err := h.service.LoginWith2FA(c.Request.Context(), req.ChallengeToken, req.Code, client)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Đăng nhập thành công", tokens)
+	respondWithTokens(c, "Đăng nhập thành công", tokens)
+}
+
+// clientInfoFromRequest gom thông tin thiết bị để tạo phiên đăng nhập
+func clientInfoFromRequest(c *gin.Context, deviceName string) services.ClientInfo {
+	return services.ClientInfo{
+		DeviceName: deviceName,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+	}
+}
+
+// respondWithTokens trả cặp token trong JSON body, hoặc ghi vào cookie HttpOnly khi bật auth.cookie.enabled
+// (body khi đó chỉ chứa CSRF token, JavaScript không bao giờ chạm được vào token)
+func respondWithTokens(c *gin.Context, message string, tokens *services.TokenDetails) {
+	if !utils.CookieAuthEnabled() {
+		response.Success(c, http.StatusOK, message, tokens)
+		return
+	}
+
+	csrfToken, err := utils.SetAuthCookies(c, tokens.AccessToken, tokens.RefreshToken)
+	if err != nil {
+		response.Error(c, custom_error.ErrInternalServer)
+		return
+	}
+	response.Success(c, http.StatusOK, message, gin.H{"csrf_token": csrfToken})
 }
 
 // RefreshToken xử lý requét cấp lại token
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	_ = c.ShouldBindJSON(&req)
+
+	// Chế độ cookie: Refresh Token nằm trong cookie HttpOnly, request phải kèm CSRF token
+	if req.RefreshToken == "" && utils.CookieAuthEnabled() {
+		req.RefreshToken, _ = c.Cookie(utils.RefreshTokenCookie)
+		if req.RefreshToken != "" && !utils.ValidCSRF(c) {
+			response.Error(c, custom_error.ErrInvalidCSRFToken)
+			return
+		}
+	}
+	if req.RefreshToken == "" {
 		response.Error(c, custom_error.ErrInvalidRequest)
 		return
 	}
@@ -87,7 +171,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Làm mới token thành công", tokens)
+	respondWithTokens(c, "Làm mới token thành công", tokens)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
@@ -98,13 +182,22 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	// Gọi Service hủy Token
-	if err := h.service.RevokeToken(c.Request.Context(), userID); err != nil {
+	sessionID, err := utils.GetSessionIDFromContext(c)
+	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Đăng xuất an toàn trên toàn hệ thống", nil)
+	// Gọi Service hủy phiên hiện tại (các thiết bị khác không bị ảnh hưởng)
+	if err := h.service.RevokeToken(c.Request.Context(), userID, sessionID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if utils.CookieAuthEnabled() {
+		utils.ClearAuthCookies(c)
+	}
+	response.Success(c, http.StatusOK, "Đăng xuất thành công", nil)
 }
 
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
@@ -115,6 +208,15 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	}
 
 	// Gọi service
+	if req.Phone != "" {
+		if err := h.service.ForgotPasswordByPhone(c.Request.Context(), req.Phone); err != nil {
+			response.Error(c, err)
+			return
+		}
+		response.Success(c, http.StatusOK, "Nếu số điện thoại đã được xác thực, mã khôi phục đã được gửi qua SMS.", nil)
+		return
+	}
+
 	err := h.service.ForgotPassword(c.Request.Context(), req.Email)
 	if err != nil {
 		response.Error(c, err)
@@ -133,7 +235,12 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	err := h.service.ResetPassword(c.Request.Context(), req.OTP, req.NewPassword)
+	var err error
+	if req.Phone != "" {
+		err = h.service.ResetPasswordByPhone(c.Request.Context(), req.Phone, req.OTP, req.NewPassword)
+	} else {
+		err = h.service.ResetPassword(c.Request.Context(), req.Email, req.OTP, req.NewPassword)
+	}
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -141,3 +248,65 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, "Đặt lại mật khẩu thành công. Vui lòng đăng nhập lại.", nil)
 }
+
+// VerifyEmail nhận token từ link trong email (GET ?token=...) hoặc từ Frontend (POST JSON)
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Xác thực email thành công", nil)
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// Luôn trả về thành công dù email có tồn tại hay không
+	response.Success(c, http.StatusOK, "Nếu email hợp lệ và chưa được xác thực, link xác thực mới đã được gửi.", nil)
+}
+
+// POST /api/v1/users/:id/impersonate
+// Cấp Access Token ngắn hạn để admin xem hệ thống dưới danh nghĩa user (không có Refresh Token)
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	sessionID, err := utils.GetSessionIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	token, err := h.service.Impersonate(c.Request.Context(), actorID, sessionID, uint(userID))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Bắt đầu giả lập người dùng", token)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-core-api/internal/services"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConsumeMagicLinkRequest struct {
+	Token      string `json:"token" binding:"required"`
+	DeviceName string `json:"device_name" binding:"max=100"`
+}
+
+// POST /api/v1/auth/magic-link
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.RequestMagicLink(c.Request.Context(), req.Email); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// Luôn trả về thành công dù email có tồn tại hay không
+	response.Success(c, http.StatusOK, "Nếu email hợp lệ, link đăng nhập đã được gửi tới hòm thư của bạn.", nil)
+}
+
+// GET /api/v1/auth/magic-link/consume
+// Link cũ trỏ thẳng vào API: không tiêu token ở GET mà chuyển sang trang Frontend để trang đó gọi POST
+func (h *AuthHandler) MagicLinkLanding(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	c.Redirect(http.StatusFound, services.MagicLinkURL(token))
+}
+
+// POST /api/v1/auth/magic-link/consume
+// Trang Frontend gửi token lấy từ link trong email, trả về token giống hệt /auth/login
+func (h *AuthHandler) ConsumeMagicLink(c *gin.Context) {
+	var req ConsumeMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	client := clientInfoFromRequest(c, req.DeviceName)
+	tokens, challenge, err := h.service.ConsumeMagicLink(c.Request.Context(), req.Token, client)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if challenge != nil {
+		response.Success(c, http.StatusOK, "Vui lòng nhập mã xác thực 2 lớp", challenge)
+		return
+	}
+
+	respondWithTokens(c, "Đăng nhập thành công", tokens)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/api/v1/auth/oauth"
+)
+
+type OAuthCallbackRequest struct {
+	Code       string `form:"code"`
+	State      string `form:"state"`
+	Error      string `form:"error"` // Provider trả về khi user từ chối cấp quyền
+	DeviceName string `form:"device_name" binding:"max=100"`
+}
+
+// OAuthRedirect chuyển hướng user sang trang đăng nhập của provider.
+// State/nonce/PKCE verifier đã ký được giữ trong cookie HttpOnly, chỉ gửi lại cho callback
+func (h *AuthHandler) OAuthRedirect(c *gin.Context) {
+	start, err := h.service.BeginOAuth(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// SameSite=Lax để cookie vẫn được gửi khi provider chuyển hướng ngược về callback
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, start.StateToken, start.ExpiresIn, oauthStateCookiePath, "", secureCookies(), true)
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// OAuthCallback nhận code từ provider và đăng nhập, kết quả giống hệt /auth/login
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	stateToken, _ := c.Cookie(oauthStateCookie)
+	// State chỉ dùng một lần: xoá cookie dù kết quả thế nào
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, oauthStateCookiePath, "", secureCookies(), true)
+
+	if req.Error != "" {
+		response.Error(c, custom_error.ErrOAuthFailed)
+		return
+	}
+	if req.Code == "" || req.State == "" || stateToken == "" {
+		response.Error(c, custom_error.ErrOAuthStateInvalid)
+		return
+	}
+
+	client := clientInfoFromRequest(c, req.DeviceName)
+	tokens, challenge, err := h.service.CompleteOAuth(c.Request.Context(), c.Param("provider"), req.Code, req.State, stateToken, client)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if challenge != nil {
+		response.Success(c, http.StatusOK, "Vui lòng nhập mã xác thực 2 lớp", challenge)
+		return
+	}
+
+	respondWithTokens(c, "Đăng nhập thành công", tokens)
+}
+
+// secureCookies bật cờ Secure khi hệ thống chạy sau HTTPS
+func secureCookies() bool {
+	return strings.HasPrefix(config.AppConfig.Server.Domain, "https://")
+}
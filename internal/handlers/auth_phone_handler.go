@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerifyPhoneRequest struct {
+	OTP string `json:"otp" binding:"required,len=6"`
+}
+
+// POST /api/v1/users/me/phone/send-otp
+// Gửi mã xác thực qua SMS tới số điện thoại đã lưu trong hồ sơ (cập nhật qua PUT /users/me)
+func (h *AuthHandler) SendPhoneOTP(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.SendPhoneOTP(c.Request.Context(), userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Mã xác thực đã được gửi qua SMS", nil)
+}
+
+// POST /api/v1/users/me/phone/verify
+func (h *AuthHandler) VerifyPhone(c *gin.Context) {
+	var req VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.VerifyPhone(c.Request.Context(), userID, req.OTP); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Xác thực số điện thoại thành công", nil)
+}
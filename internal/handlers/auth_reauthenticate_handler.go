@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReauthenticateRequest struct {
+	Password string `json:"password" binding:"required,max=128"`
+	Code     string `json:"code"` // Mã TOTP/recovery code, bắt buộc khi tài khoản đã bật 2FA
+}
+
+// POST /api/v1/auth/reauthenticate
+// Nhập lại mật khẩu trên phiên hiện tại, trả về cặp token mới mang auth_time để thực hiện thao tác nhạy cảm
+func (h *AuthHandler) Reauthenticate(c *gin.Context) {
+	var req ReauthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	sessionID, err := utils.GetSessionIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	client := clientInfoFromRequest(c, "")
+	tokens, err := h.service.Reauthenticate(c.Request.Context(), userID, sessionID, req.Password, req.Code, client)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	respondWithTokens(c, "Xác thực lại thành công", tokens)
+}
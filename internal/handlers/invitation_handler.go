@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-core-api/internal/services"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	service services.InvitationService
+}
+
+func NewInvitationHandler(service services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: service}
+}
+
+type CreateInvitationRequest struct {
+	Email         string `json:"email" binding:"required,email"`
+	Role          string `json:"role" binding:"required"`
+	ExpiresInDays int    `json:"expires_in_days"` // Bỏ trống = 7 ngày
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,max=128"` // Độ mạnh do PasswordPolicy kiểm tra
+	FullName string `json:"full_name" binding:"max=100"`
+}
+
+// POST /api/v1/users/invitations
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	inviterID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	invitation, err := h.service.Invite(c.Request.Context(), inviterID, services.InviteInput{
+		Email:         req.Email,
+		Role:          req.Role,
+		ExpiresInDays: req.ExpiresInDays,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Đã gửi lời mời", invitation)
+}
+
+// GET /api/v1/users/invitations
+// Danh sách lời mời chưa được chấp nhận (kể cả đã hết hạn, có thể gửi lại)
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.service.ListPending(c.Request.Context())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Lấy danh sách lời mời thành công", invitations)
+}
+
+// POST /api/v1/users/invitations/:id/resend
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	invitation, err := h.service.Resend(c.Request.Context(), uint(id))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã gửi lại lời mời", invitation)
+}
+
+// DELETE /api/v1/users/invitations/:id
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), uint(id)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã thu hồi lời mời", nil)
+}
+
+// POST /api/v1/auth/invitations/accept
+// Người được mời đặt mật khẩu để kích hoạt tài khoản, sau đó đăng nhập như bình thường
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.Accept(c.Request.Context(), req.Token, req.Password, req.FullName); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Kích hoạt tài khoản thành công, vui lòng đăng nhập", nil)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-core-api/internal/services"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoginHistoryHandler struct {
+	service services.LoginHistoryService
+}
+
+func NewLoginHistoryHandler(service services.LoginHistoryService) *LoginHistoryHandler {
+	return &LoginHistoryHandler{service: service}
+}
+
+// GET /api/v1/users/me/login-history
+func (h *LoginHistoryHandler) ListMyHistory(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	h.respondHistory(c, userID)
+}
+
+// GET /api/v1/users/:id/login-history
+func (h *LoginHistoryHandler) ListUserHistory(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	h.respondHistory(c, uint(userID))
+}
+
+func (h *LoginHistoryHandler) respondHistory(c *gin.Context, userID uint) {
+	pagination := utils.GeneratePaginationFromRequest(c)
+
+	attempts, total, totalPages, err := h.service.ListByUser(c.Request.Context(), userID, pagination)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Lấy lịch sử đăng nhập thành công", gin.H{
+		"items": attempts,
+		"meta": gin.H{
+			"total":       total,
+			"total_pages": totalPages,
+			"page":        pagination.Page,
+			"limit":       pagination.Limit,
+		},
+	})
+}
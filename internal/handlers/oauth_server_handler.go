@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/services"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// scopeDescriptions là mô tả hiển thị trên màn hình đồng ý cấp quyền
+var scopeDescriptions = map[string]string{
+	models.ScopeOpenID:        "Verify your identity",
+	models.ScopeProfile:       "View your name and avatar",
+	models.ScopeEmail:         "View your email address",
+	models.ScopeOfflineAccess: "Stay signed in when you are not using the app",
+	models.ScopeProfileRead:   "Read your profile",
+	models.ScopeProfileWrite:  "Update your profile",
+	models.ScopeUpload:        "Upload images on your behalf",
+	models.ScopeUsersRead:     "View users (admins only)",
+	models.ScopeUsersWrite:    "Manage users (admins only)",
+}
+
+type OAuthServerHandler struct {
+	service services.OAuthServerService
+	auth    services.AuthService
+}
+
+func NewOAuthServerHandler(service services.OAuthServerService, auth services.AuthService) *OAuthServerHandler {
+	return &OAuthServerHandler{service: service, auth: auth}
+}
+
+type AuthorizeQuery struct {
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	ResponseType        string `form:"response_type"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+func (q AuthorizeQuery) toService() services.AuthorizeRequest {
+	return services.AuthorizeRequest{
+		ClientID:            q.ClientID,
+		RedirectURI:         q.RedirectURI,
+		ResponseType:        q.ResponseType,
+		Scope:               q.Scope,
+		State:               q.State,
+		Nonce:               q.Nonce,
+		CodeChallenge:       q.CodeChallenge,
+		CodeChallengeMethod: q.CodeChallengeMethod,
+	}
+}
+
+// params trả lại tham số gốc để màn hình đồng ý gửi kèm trong form (hidden input)
+func (q AuthorizeQuery) params() map[string]string {
+	return map[string]string{
+		"client_id":             q.ClientID,
+		"redirect_uri":          q.RedirectURI,
+		"response_type":         q.ResponseType,
+		"scope":                 q.Scope,
+		"state":                 q.State,
+		"nonce":                 q.Nonce,
+		"code_challenge":        q.CodeChallenge,
+		"code_challenge_method": q.CodeChallengeMethod,
+	}
+}
+
+type AuthorizeSubmitRequest struct {
+	AuthorizeQuery
+	Email     string `form:"email"`
+	Password  string `form:"password"`
+	Code      string `form:"code"`
+	Action    string `form:"action"`
+	CSRFToken string `form:"csrf_token"` // Chỉ có khi màn hình đồng ý dùng phiên đăng nhập sẵn có
+}
+
+type TokenForm struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenLookupForm là form của /oauth/introspect và /oauth/revoke
+type TokenLookupForm struct {
+	Token        string `form:"token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type RegisterClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+	Public       bool     `json:"public"` // SPA / mobile app không giữ được secret, bắt buộc PKCE
+}
+
+// GET /.well-known/openid-configuration
+func (h *OAuthServerHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.service.Discovery())
+}
+
+// GET /oauth/authorize
+// Kiểm tra yêu cầu của client rồi hiển thị màn hình đồng ý cấp quyền.
+// User vừa đăng nhập trên trình duyệt này thì chỉ cần bấm đồng ý, ngược lại phải nhập thông tin đăng nhập
+func (h *OAuthServerHandler) AuthorizePage(c *gin.Context) {
+	var query AuthorizeQuery
+	_ = c.ShouldBindQuery(&query)
+
+	authorizeCtx, err := h.service.ValidateAuthorize(c.Request.Context(), query.toService())
+	if err != nil {
+		h.authorizeError(c, query, err)
+		return
+	}
+
+	if user, _, ok := h.sessionUser(c); ok {
+		h.renderConsent(c, query, authorizeCtx, user, user.Email, "")
+		return
+	}
+	h.renderConsent(c, query, authorizeCtx, nil, "", "")
+}
+
+// POST /oauth/authorize
+func (h *OAuthServerHandler) AuthorizeSubmit(c *gin.Context) {
+	var req AuthorizeSubmitRequest
+	_ = c.ShouldBind(&req)
+
+	ctx := c.Request.Context()
+	authorizeCtx, err := h.service.ValidateAuthorize(ctx, req.toService())
+	if err != nil {
+		h.authorizeError(c, req.AuthorizeQuery, err)
+		return
+	}
+
+	if req.Action == "deny" {
+		denied := &services.OAuthError{Code: "access_denied", Description: "Người dùng từ chối cấp quyền"}
+		c.Redirect(http.StatusFound, denied.RedirectURL(req.RedirectURI, req.State))
+		return
+	}
+
+	// Phiên sẵn có chỉ được dùng khi form mang đúng csrf_token: trang khác gửi form kèm cookie được
+	// nhưng không đọc được giá trị cookie
+	user, authTime, ok := h.sessionUser(c)
+	if !ok || !utils.ValidCSRFValue(c, req.CSRFToken) {
+		// Đăng nhập dùng chung bộ đếm sai mật khẩu, khoá tài khoản và 2FA với /auth/login
+		client := clientInfoFromRequest(c, "")
+		client.ClientID = authorizeCtx.Client.ClientID
+		user, err = h.auth.Authenticate(ctx, req.Email, req.Password, req.Code, client)
+		if err != nil {
+			var appErr *custom_error.AppError
+			if !errors.As(err, &appErr) {
+				appErr = custom_error.ErrInternalServer
+			}
+			h.renderConsent(c, req.AuthorizeQuery, authorizeCtx, nil, req.Email, appErr.Message)
+			return
+		}
+		authTime = time.Now()
+	}
+
+	redirectURL, err := h.service.Authorize(ctx, req.toService(), user, authTime)
+	if err != nil {
+		h.authorizeError(c, req.AuthorizeQuery, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// sessionUser đọc phiên đăng nhập trên trình duyệt từ cookie access_token (chỉ khi bật chế độ cookie)
+func (h *OAuthServerHandler) sessionUser(c *gin.Context) (*models.User, time.Time, bool) {
+	if !utils.CookieAuthEnabled() {
+		return nil, time.Time{}, false
+	}
+	accessToken, err := c.Cookie(utils.AccessTokenCookie)
+	if err != nil || accessToken == "" {
+		return nil, time.Time{}, false
+	}
+	return h.service.SessionUser(c.Request.Context(), accessToken)
+}
+
+// renderConsent hiển thị màn hình đồng ý. sessionUser khác nil thì ẩn ô đăng nhập và gửi kèm csrf_token
+// để POST dùng lại phiên đó
+func (h *OAuthServerHandler) renderConsent(c *gin.Context, query AuthorizeQuery, authorizeCtx *services.AuthorizeContext, sessionUser *models.User, email, message string) {
+	scopes := make([]string, 0, len(authorizeCtx.Scopes))
+	for _, scope := range authorizeCtx.Scopes {
+		if description, ok := scopeDescriptions[scope]; ok {
+			scopes = append(scopes, description)
+		} else {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	redirectHost := query.RedirectURI
+	if u, err := url.Parse(query.RedirectURI); err == nil {
+		redirectHost = u.Host
+	}
+
+	status := http.StatusOK
+	if message != "" {
+		status = http.StatusUnauthorized
+	}
+
+	params := query.params()
+	signedIn := false
+	if sessionUser != nil {
+		if csrfToken, err := c.Cookie(utils.CSRFCookie); err == nil && csrfToken != "" {
+			params["csrf_token"] = csrfToken
+			signedIn = true
+		}
+	}
+
+	h.renderHTML(c, status, "oauth_consent.html", map[string]interface{}{
+		"ClientName":   authorizeCtx.Client.Name,
+		"SignedIn":     signedIn,
+		"Scopes":       scopes,
+		"Params":       params,
+		"Action":       "/oauth/authorize",
+		"Email":        email,
+		"Error":        message,
+		"RedirectHost": redirectHost,
+	})
+}
+
+// authorizeError trả lỗi về client qua redirect_uri khi đã xác minh được nó, ngược lại hiển thị trang lỗi
+func (h *OAuthServerHandler) authorizeError(c *gin.Context, query AuthorizeQuery, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		oauthErr = &services.OAuthError{Status: http.StatusInternalServerError, Code: "server_error", Description: "Đã có lỗi xảy ra, vui lòng thử lại sau"}
+	}
+
+	if oauthErr.Redirectable {
+		c.Redirect(http.StatusFound, oauthErr.RedirectURL(query.RedirectURI, query.State))
+		return
+	}
+
+	h.renderHTML(c, oauthErr.Status, "oauth_error.html", oauthErr)
+}
+
+func (h *OAuthServerHandler) renderHTML(c *gin.Context, status int, filename string, data interface{}) {
+	body, err := templates.Render(filename, data)
+	if err != nil {
+		logger.Error("Lỗi render trang OAuth", zap.String("template", filename), zap.Error(err))
+		c.String(http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	// Trang nhập mật khẩu không được nhúng vào iframe (clickjacking) và không được cache
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", []byte(body))
+}
+
+// POST /oauth/token
+// Client xác thực bằng HTTP Basic (client_secret_basic) hoặc client_id/client_secret trong form (client_secret_post)
+func (h *OAuthServerHandler) Token(c *gin.Context) {
+	var form TokenForm
+	_ = c.ShouldBind(&form)
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	basicAuth := clientCredentials(c, &form.ClientID, &form.ClientSecret)
+	tokens, err := h.service.Token(c.Request.Context(), services.TokenRequest{
+		GrantType:    form.GrantType,
+		Code:         form.Code,
+		RedirectURI:  form.RedirectURI,
+		CodeVerifier: form.CodeVerifier,
+		RefreshToken: form.RefreshToken,
+		Scope:        form.Scope,
+		ClientID:     form.ClientID,
+		ClientSecret: form.ClientSecret,
+	}, clientInfoFromRequest(c, ""))
+	if err != nil {
+		writeOAuthError(c, err, basicAuth)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// POST /oauth/introspect
+// RFC 7662: client confidential (VD: API gateway) hỏi token còn hiệu lực không, trả về active/sub/role/exp
+func (h *OAuthServerHandler) Introspect(c *gin.Context) {
+	var form TokenLookupForm
+	_ = c.ShouldBind(&form)
+
+	c.Header("Cache-Control", "no-store")
+	basicAuth := clientCredentials(c, &form.ClientID, &form.ClientSecret)
+
+	result, err := h.service.Introspect(c.Request.Context(), services.TokenLookupRequest{
+		Token:        form.Token,
+		ClientID:     form.ClientID,
+		ClientSecret: form.ClientSecret,
+	})
+	if err != nil {
+		writeOAuthError(c, err, basicAuth)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// POST /oauth/revoke
+// RFC 7009: thu hồi Access/Refresh Token đã cấp cho client, luôn trả về 200 kể cả khi token đã hết hiệu lực
+func (h *OAuthServerHandler) Revoke(c *gin.Context) {
+	var form TokenLookupForm
+	_ = c.ShouldBind(&form)
+
+	basicAuth := clientCredentials(c, &form.ClientID, &form.ClientSecret)
+
+	err := h.service.Revoke(c.Request.Context(), services.TokenLookupRequest{
+		Token:        form.Token,
+		ClientID:     form.ClientID,
+		ClientSecret: form.ClientSecret,
+	})
+	if err != nil {
+		writeOAuthError(c, err, basicAuth)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// clientCredentials đọc client_id/client_secret từ HTTP Basic (client_secret_basic) nếu có,
+// ngược lại giữ giá trị lấy từ form (client_secret_post). Trả về true khi client dùng Basic
+func clientCredentials(c *gin.Context, clientID, clientSecret *string) bool {
+	id, secret, ok := c.Request.BasicAuth()
+	if !ok {
+		return false
+	}
+	// RFC 6749 mục 2.3.1: client_id và client_secret được form-urlencode trước khi ghép Basic
+	*clientID, _ = url.QueryUnescape(id)
+	*clientSecret, _ = url.QueryUnescape(secret)
+	return true
+}
+
+// writeOAuthError trả lỗi theo định dạng {"error": "..."} của RFC 6749 mục 5.2
+func writeOAuthError(c *gin.Context, err error, basicAuth bool) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	if oauthErr.Code == "invalid_client" && basicAuth {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	c.JSON(oauthErr.Status, oauthErr)
+}
+
+// GET/POST /userinfo
+// Trả về claim thô theo OIDC Core 5.3, chỉ gồm các claim thuộc scope đã được đồng ý
+func (h *OAuthServerHandler) UserInfo(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// Access Token của chính hệ thống (không qua OAuth) được xem như có đủ scope
+	scopes := models.OIDCScopes
+	if granted, ok := c.Get("scopes"); ok {
+		scopes, _ = granted.([]string)
+	}
+
+	claims, err := h.service.UserInfo(c.Request.Context(), userID, scopes)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, claims)
+}
+
+// GET /api/v1/admin/oauth/clients
+func (h *OAuthServerHandler) ListClients(c *gin.Context) {
+	clients, err := h.service.ListClients(c.Request.Context())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Lấy danh sách ứng dụng thành công", clients)
+}
+
+// POST /api/v1/admin/oauth/clients
+func (h *OAuthServerHandler) RegisterClient(c *gin.Context) {
+	var req RegisterClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	created, err := h.service.RegisterClient(c.Request.Context(), userID, services.RegisterClientInput{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		GrantTypes:   req.GrantTypes,
+		Public:       req.Public,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Đăng ký ứng dụng thành công. Hãy lưu lại client_secret, nó sẽ không được hiển thị lần nữa", created)
+}
+
+// DELETE /api/v1/admin/oauth/clients/:id
+func (h *OAuthServerHandler) RevokeClient(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.RevokeClient(c.Request.Context(), uint(id)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã thu hồi ứng dụng và toàn bộ token đã cấp", nil)
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-core-api/internal/services"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	service services.OrganizationService
+}
+
+func NewOrganizationHandler(service services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{service: service}
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type SwitchOrganizationRequest struct {
+	OrganizationID uint `json:"organization_id"` // 0 = không chọn tổ chức nào
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type AcceptOrgInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// GET /api/v1/orgs
+// Danh sách tổ chức mà user hiện tại là thành viên
+func (h *OrganizationHandler) ListMyOrganizations(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	memberships, err := h.service.ListMyOrganizations(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Lấy danh sách tổ chức thành công", memberships)
+}
+
+// POST /api/v1/orgs
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	membership, err := h.service.CreateOrganization(c.Request.Context(), userID, req.Name)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Tạo tổ chức thành công", membership)
+}
+
+// POST /api/v1/orgs/switch
+// Đổi tổ chức đang làm việc của phiên hiện tại, trả về cặp token mới mang claim org_id
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	var req SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	sessionID, err := utils.GetSessionIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	tokens, err := h.service.SwitchOrganization(c.Request.Context(), userID, sessionID, req.OrganizationID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	respondWithTokens(c, "Đã chuyển tổ chức", tokens)
+}
+
+// GET /api/v1/orgs/current/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	organizationID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), organizationID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Lấy danh sách thành viên thành công", members)
+}
+
+// POST /api/v1/orgs/current/invitations
+// Gửi lời mời qua email, người nhận chỉ trở thành thành viên khi tự chấp nhận
+func (h *OrganizationHandler) InviteMember(c *gin.Context) {
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	organizationID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	inviterID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	invitation, err := h.service.InviteMember(c.Request.Context(), organizationID, inviterID, c.GetString("org_role"), req.Email, req.Role)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Đã gửi lời mời", invitation)
+}
+
+// POST /api/v1/orgs/invitations/accept
+// User đăng nhập bằng đúng email được mời chấp nhận lời mời (token lấy từ link trong email)
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptOrgInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	membership, err := h.service.AcceptInvitation(c.Request.Context(), userID, req.Token)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Đã tham gia tổ chức", membership)
+}
+
+// PUT /api/v1/orgs/current/members/:user_id
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	organizationID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.UpdateMemberRole(c.Request.Context(), organizationID, c.GetString("org_role"), uint(memberID), req.Role); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Cập nhật role thành viên thành công", nil)
+}
+
+// DELETE /api/v1/orgs/current/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	organizationID, err := utils.GetOrganizationIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), organizationID, c.GetString("org_role"), uint(memberID)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã xoá thành viên khỏi tổ chức", nil)
+}
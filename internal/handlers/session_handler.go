@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-core-api/internal/services"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	service services.SessionService
+}
+
+func NewSessionHandler(service services.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+// GET /api/v1/users/me/sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	sessionID, _ := utils.GetSessionIDFromContext(c)
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Lấy danh sách phiên đăng nhập thành công", sessions)
+}
+
+// DELETE /api/v1/users/me/sessions/:id
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.RevokeSession(c.Request.Context(), userID, uint(id)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã đăng xuất thiết bị", nil)
+}
+
+// DELETE /api/v1/users/me/sessions (Đăng xuất khỏi mọi thiết bị khác)
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	sessionID, err := utils.GetSessionIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.RevokeOtherSessions(c.Request.Context(), userID, sessionID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã đăng xuất khỏi tất cả thiết bị khác", nil)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"go-core-api/internal/services"
 	"go-core-api/pkg/custom_error"
@@ -14,7 +15,7 @@ import (
 
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required,max=128"` // Độ mạnh do PasswordPolicy kiểm tra
 }
 
 type UserHandler struct {
@@ -31,6 +32,11 @@ type AdminUpdateUserRequest struct {
 	Role string `json:"role" binding:"required"`
 }
 
+type SuspendUserRequest struct {
+	Reason string     `json:"reason" binding:"required,max=500"`
+	Until  *time.Time `json:"until"` // Bỏ trống = đình chỉ vô thời hạn
+}
+
 func NewUserHandler(service services.UserService) *UserHandler {
 	return &UserHandler{service: service}
 }
@@ -162,7 +168,13 @@ func (h *UserHandler) AdminUpdateUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.AdminUpdateUser(c.Request.Context(), uint(id), req.Role); err != nil {
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.AdminUpdateUser(c.Request.Context(), actorID, uint(id), req.Role); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -180,12 +192,16 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	currentAdminID, err := utils.GetUserIDFromContext(c)
-	if err == nil && uint(targetID) == currentAdminID {
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if uint(targetID) == currentAdminID {
 		response.Error(c, custom_error.ErrCannotDeleteSelf)
 		return
 	}
 
-	if err := h.service.DeleteUser(c.Request.Context(), uint(targetID)); err != nil {
+	if err := h.service.DeleteUser(c.Request.Context(), currentAdminID, uint(targetID)); err != nil {
 		response.Error(c, err)
 		return
 	}
@@ -204,15 +220,88 @@ func (h *UserHandler) PurgeUser(c *gin.Context) {
 
 	// Đảm bảo Admin không tự purge chính mình
 	currentAdminID, err := utils.GetUserIDFromContext(c)
-	if err == nil && uint(targetID) == currentAdminID {
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if uint(targetID) == currentAdminID {
 		response.Error(c, custom_error.ErrCannotDeleteSelf)
 		return
 	}
 
-	if err := h.service.PurgeUser(c.Request.Context(), uint(targetID)); err != nil {
+	if err := h.service.PurgeUser(c.Request.Context(), currentAdminID, uint(targetID)); err != nil {
 		response.Error(c, err)
 		return
 	}
 
 	response.Success(c, http.StatusOK, "Đã dọn dẹp (wipe) dữ liệu người dùng vĩnh viễn", nil)
 }
+
+// POST /api/v1/users/:id/unlock
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.UnlockUser(c.Request.Context(), uint(id)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã mở khoá đăng nhập cho người dùng", nil)
+}
+
+// POST /api/v1/users/:id/suspend
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	var req SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.SuspendUser(c.Request.Context(), actorID, uint(id), req.Reason, req.Until); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã đình chỉ tài khoản", nil)
+}
+
+// POST /api/v1/users/:id/unsuspend
+func (h *UserHandler) UnsuspendUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.Error(c, custom_error.ErrInvalidRequest)
+		return
+	}
+
+	actorID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.service.UnsuspendUser(c.Request.Context(), actorID, uint(id)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Đã gỡ đình chỉ tài khoản", nil)
+}
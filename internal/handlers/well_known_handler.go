@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-core-api/pkg/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WellKnownHandler phục vụ các endpoint công khai dưới /.well-known
+type WellKnownHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+func NewWellKnownHandler(keys *jwtkeys.KeySet) *WellKnownHandler {
+	return &WellKnownHandler{keys: keys}
+}
+
+// GET /.well-known/jwks.json
+// Trả về JWKS thô theo RFC 7517 (không bọc trong response chuẩn) để thư viện JWT của service khác đọc trực tiếp
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -1,23 +1,58 @@
 package middlewares
 
 import (
-	"fmt"
+	"context"
+	"crypto/subtle"
 	"strings"
+	"time"
 
+	"go-core-api/internal/models"
 	"go-core-api/internal/repositories"
+	"go-core-api/internal/services"
+	"go-core-api/pkg/config"
 	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/jwtkeys"
+	"go-core-api/pkg/logger"
 	"go-core-api/pkg/response"
+	"go-core-api/pkg/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
-func RequireAuth(secret string, userRepo repositories.UserRepository) gin.HandlerFunc {
+// Các cách xác thực được RequireAuth ghi vào context ("auth_method")
+const (
+	AuthMethodJWT   = "jwt"
+	AuthMethodPAT   = "pat"
+	AuthMethodOAuth = "oauth" // Access Token cấp cho ứng dụng khác qua /oauth/token
+)
+
+// patTouchInterval giới hạn tần suất ghi last_used_at để không biến mỗi request thành 1 câu UPDATE
+const patTouchInterval = time.Minute
+
+// RequireAuth chấp nhận Access Token (JWT) hoặc Personal Access Token qua
+// "Authorization: Bearer pat_..." hoặc header "X-API-Key", cuối cùng là cookie access_token (chế độ cookie).
+// Context luôn có "user_id" (user hiệu lực) và "actor_id" (người thực sự gửi request, khác user_id khi admin giả lập)
+func RequireAuth(
+	keys *jwtkeys.KeySet,
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	tokenRepo repositories.PersonalAccessTokenRepository,
+	roleRepo repositories.RoleRepository,
+	orgRepo repositories.OrganizationRepository,
+	impersonationRepo repositories.ImpersonationLogRepository,
+) gin.HandlerFunc {
+	validator := services.NewTokenValidator(userRepo, sessionRepo, roleRepo, orgRepo)
+
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticatePAT(c, apiKey, userRepo, tokenRepo, roleRepo)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			response.Error(c, custom_error.ErrUnauthorized)
-			c.Abort()
+			authenticateCookie(c, keys, validator, impersonationRepo)
 			return
 		}
 
@@ -29,53 +64,300 @@ func RequireAuth(secret string, userRepo repositories.UserRepository) gin.Handle
 		}
 
 		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("phương thức mã hoá không hợp lệ")
-			}
-			return []byte(secret), nil
+		if utils.IsPersonalToken(tokenString) {
+			authenticatePAT(c, tokenString, userRepo, tokenRepo, roleRepo)
+			return
+		}
+		authenticateJWT(c, tokenString, keys, validator, impersonationRepo)
+	}
+}
+
+// authenticateCookie xác thực bằng cookie access_token (chế độ auth.cookie.enabled).
+// Trình duyệt tự gửi cookie kèm cả request do trang khác khởi tạo, nên request ghi dữ liệu phải có CSRF token
+func authenticateCookie(
+	c *gin.Context,
+	keys *jwtkeys.KeySet,
+	validator services.TokenValidator,
+	impersonationRepo repositories.ImpersonationLogRepository,
+) {
+	tokenString, err := c.Cookie(utils.AccessTokenCookie)
+	if !utils.CookieAuthEnabled() || err != nil || tokenString == "" {
+		response.Error(c, custom_error.ErrUnauthorized)
+		c.Abort()
+		return
+	}
+
+	if !utils.IsSafeMethod(c.Request.Method) && !utils.ValidCSRF(c) {
+		response.Error(c, custom_error.ErrInvalidCSRFToken)
+		return
+	}
+	authenticateJWT(c, tokenString, keys, validator, impersonationRepo)
+}
+
+func authenticateJWT(
+	c *gin.Context,
+	tokenString string,
+	keys *jwtkeys.KeySet,
+	validator services.TokenValidator,
+	impersonationRepo repositories.ImpersonationLogRepository,
+) {
+	rawClaims, err := keys.Parse(tokenString)
+	if err != nil {
+		response.Error(c, custom_error.New(401, "ERR_TOKEN_INVALID", "Token hết hạn hoặc bị can thiệp"))
+		c.Abort()
+		return
+	}
+
+	if rawClaims["token_type"] != "access" {
+		response.Error(c, custom_error.New(401, "ERR_WRONG_TOKEN_TYPE", "Sử dụng sai loại Token"))
+		c.Abort()
+		return
+	}
+
+	claims, err := services.ParseUserTokenClaims(rawClaims)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if claims.Impersonating {
+		// Ghi nhật ký cả request bị từ chối ở các bước kiểm tra bên dưới (phiên bị thu hồi,
+		// hết quyền giả lập, quyền đổi...), không chỉ request đi tới handler
+		defer recordImpersonatedRequest(c, impersonationRepo, claims.ActorID, claims.UserID, claims.SessionID)
+	}
+
+	// Cùng bộ kiểm tra với /oauth/introspect: TokenVersion, đình chỉ, phiên, quyền giả lập, perm_version, membership
+	validated, err := validator.Validate(c.Request.Context(), claims)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	user := validated.User
+
+	c.Set("user_id", claims.UserID)
+	c.Set("actor_id", claims.ActorID)
+	c.Set("impersonated", claims.Impersonating)
+	c.Set("session_id", claims.SessionID)
+	c.Set("role", rawClaims["role"])
+	c.Set("permissions", validated.Role.PermissionNames())
+	c.Set("email_verified", user.EmailVerifiedAt != nil)
+	if authTime, ok := rawClaims["auth_time"].(float64); ok && claims.ClientID == "" && !claims.Impersonating {
+		c.Set("auth_time", time.Unix(int64(authTime), 0))
+	}
+
+	// Tổ chức đang làm việc: role trong tổ chức lấy theo DB.
+	// Mọi truy vấn user phía sau (qua c.Request.Context()) chỉ thấy thành viên của tổ chức này
+	if validated.Membership != nil {
+		c.Set("org_id", claims.OrganizationID)
+		c.Set("org_role", validated.Membership.Role)
+		c.Request = c.Request.WithContext(repositories.WithTenant(c.Request.Context(), claims.OrganizationID))
+	}
+
+	// Token cấp cho ứng dụng khác chỉ có quyền trong phạm vi scope user đã đồng ý
+	if claims.ClientID != "" {
+		scope, _ := rawClaims["scope"].(string)
+		c.Set("auth_method", AuthMethodOAuth)
+		c.Set("client_id", claims.ClientID)
+		c.Set("scopes", strings.Fields(scope))
+	} else {
+		c.Set("auth_method", AuthMethodJWT)
+	}
+	c.Next()
+}
+
+// recordImpersonatedRequest ghi nhật ký request làm dưới danh nghĩa user khác (kể cả request bị từ chối)
+func recordImpersonatedRequest(c *gin.Context, repo repositories.ImpersonationLogRepository, actorID, userID, sessionID uint) {
+	entry := &models.ImpersonationLog{
+		ActorID:   actorID,
+		UserID:    userID,
+		SessionID: sessionID,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Status:    c.Writer.Status(),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+
+	logger.Info("Impersonated request",
+		zap.Uint("actor_id", actorID),
+		zap.Uint("user_id", userID),
+		zap.String("method", entry.Method),
+		zap.String("path", entry.Path),
+		zap.Int("status", entry.Status),
+	)
+
+	utils.RunInBackground(func() {
+		if err := repo.Create(context.Background(), entry); err != nil {
+			logger.Error("Không thể ghi nhật ký giả lập", zap.Uint("actor_id", actorID), zap.Error(err))
+		}
+	})
+}
+
+func authenticatePAT(
+	c *gin.Context,
+	rawToken string,
+	userRepo repositories.UserRepository,
+	tokenRepo repositories.PersonalAccessTokenRepository,
+	roleRepo repositories.RoleRepository,
+) {
+	invalid := custom_error.New(401, "ERR_API_TOKEN_INVALID", "API token không hợp lệ, đã hết hạn hoặc đã bị thu hồi")
+
+	prefix, ok := utils.ParsePersonalToken(rawToken)
+	if !ok {
+		response.Error(c, invalid)
+		c.Abort()
+		return
+	}
+
+	token, err := tokenRepo.FindByPrefix(c.Request.Context(), prefix)
+	if err != nil || subtle.ConstantTimeCompare([]byte(token.TokenHash), []byte(utils.HashToken(rawToken))) != 1 || !token.IsActive() {
+		response.Error(c, invalid)
+		c.Abort()
+		return
+	}
+
+	user, err := userRepo.FindByID(c.Request.Context(), token.UserID)
+	if err != nil {
+		response.Error(c, invalid)
+		c.Abort()
+		return
+	}
+	if user.IsSuspended() {
+		response.Error(c, custom_error.ErrAccountSuspended)
+		c.Abort()
+		return
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > patTouchInterval {
+		tokenID := token.ID
+		utils.RunInBackground(func() {
+			_ = tokenRepo.TouchLastUsed(context.Background(), tokenID, time.Now())
 		})
+	}
 
-		if err != nil || !token.Valid {
-			response.Error(c, custom_error.New(401, "ERR_TOKEN_INVALID", "Token hết hạn hoặc bị can thiệp"))
-			c.Abort()
+	role, ok := loadRole(c, roleRepo, user.Role)
+	if !ok {
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("actor_id", user.ID)
+	c.Set("role", user.Role)
+	c.Set("permissions", role.PermissionNames())
+	c.Set("email_verified", user.EmailVerifiedAt != nil)
+	c.Set("auth_method", AuthMethodPAT)
+	c.Set("scopes", token.Scopes)
+	c.Next()
+}
+
+// loadRole lấy role hiện tại của user kèm danh sách quyền
+func loadRole(c *gin.Context, roleRepo repositories.RoleRepository, name string) (*models.Role, bool) {
+	role, err := roleRepo.FindByName(c.Request.Context(), name)
+	if err != nil {
+		response.Error(c, custom_error.ErrForbidden)
+		return nil, false
+	}
+	return role, true
+}
+
+// RequireScope giới hạn route theo scope khi request dùng API token hoặc token cấp cho OAuth client.
+// Request đăng nhập bằng JWT thông thường luôn được đi qua
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if method := c.GetString("auth_method"); method != AuthMethodPAT && method != AuthMethodOAuth {
+			c.Next()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["token_type"] != "access" {
-			response.Error(c, custom_error.New(401, "ERR_WRONG_TOKEN_TYPE", "Sử dụng sai loại Token"))
-			c.Abort()
-			return
+		for _, granted := range c.GetStringSlice("scopes") {
+			if granted == scope {
+				c.Next()
+				return
+			}
 		}
+		response.Error(c, custom_error.ErrInsufficientScope)
+	}
+}
 
-		// BUG FIX: Ép kiểu an toàn, tránh Panic làm sập server
-		userIDFloat, okID := claims["user_id"].(float64)
-		tokenVersionFloat, okVer := claims["token_version"].(float64)
+// DenyAPIToken chặn API token (và token cấp cho OAuth client) khỏi các thao tác quản lý
+// thông tin đăng nhập (đổi mật khẩu, 2FA, phiên đăng nhập, tạo token mới...)
+func DenyAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if method := c.GetString("auth_method"); method == AuthMethodPAT || method == AuthMethodOAuth {
+			response.Error(c, custom_error.ErrAPITokenNotAllowed)
+			return
+		}
+		c.Next()
+	}
+}
 
-		if !okID || !okVer {
-			response.Error(c, custom_error.New(401, "ERR_PAYLOAD_INVALID", "Payload của Token không hợp lệ"))
-			c.Abort()
+// DenyImpersonation chặn token giả lập khỏi các thao tác trên thông tin đăng nhập của user
+// (mật khẩu, email, 2FA, phiên, token) và khỏi việc giả lập lồng nhau
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("impersonated") {
+			response.Error(c, custom_error.ErrImpersonationNotAllowed)
 			return
 		}
+		c.Next()
+	}
+}
 
-		userID := uint(userIDFloat)
-		tokenVersion := int(tokenVersionFloat)
+// RequireRecentAuth yêu cầu user đã xác thực lại (POST /auth/reauthenticate) trong vòng maxAge (đặt sau RequireAuth).
+// Chỉ Access Token của phiên đăng nhập trực tiếp mang auth_time, nên API token, token OAuth và token giả lập luôn bị chặn
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authTime := c.GetTime("auth_time")
+		if authTime.IsZero() || time.Since(authTime) > maxAge {
+			response.Error(c, custom_error.ErrReauthenticationRequired)
+			return
+		}
+		c.Next()
+	}
+}
 
-		user, err := userRepo.FindByID(c.Request.Context(), userID)
-		if err != nil || user.TokenVersion != tokenVersion {
-			response.Error(c, custom_error.ErrUnauthorized)
-			c.Abort()
+// RequireVerifiedEmail chặn các route nhạy cảm khi user chưa xác thực email (đặt sau RequireAuth).
+// Chỉ có hiệu lực khi auth.email_verification_mode = "routes"
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.AppConfig.Auth.EmailVerificationMode == config.EmailVerificationRoutes && !c.GetBool("email_verified") {
+			response.Error(c, custom_error.ErrEmailNotVerified)
 			return
 		}
+		c.Next()
+	}
+}
+
+// RequirePermission chỉ cho đi qua khi role của user có quyền tương ứng (đặt sau RequireAuth)
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, granted := range c.GetStringSlice("permissions") {
+			if granted == permission {
+				c.Next()
+				return
+			}
+		}
+		response.Error(c, custom_error.ErrForbidden)
+	}
+}
 
-		c.Set("user_id", userID)
-		c.Set("role", claims["role"])
+// RequireOrgRole yêu cầu đang làm việc trong một tổ chức với role tối thiểu (owner > admin > member)
+func RequireOrgRole(minimum string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("org_id"); !exists {
+			response.Error(c, custom_error.ErrNoActiveOrganization)
+			return
+		}
+		if !models.OrgRoleAtLeast(c.GetString("org_role"), minimum) {
+			response.Error(c, custom_error.ErrForbidden)
+			return
+		}
 		c.Next()
 	}
 }
 
-// RequireRole phân quyền RBAC (Role-Based Access Control)
+// RequireRole phân quyền RBAC (Role-Based Access Control) theo tên role.
+// Ưu tiên RequirePermission để admin điều chỉnh được quyền mà không cần sửa code
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
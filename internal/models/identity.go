@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Identity đại diện cho bảng 'identities': liên kết một tài khoản bên ngoài (Google, GitHub, OIDC...)
+// với user trong hệ thống. Mỗi cặp provider + subject chỉ thuộc về đúng một user
+type Identity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"-"`
+	Provider  string    `gorm:"uniqueIndex:idx_identity_provider_subject;not null" json:"provider"`
+	Subject   string    `gorm:"uniqueIndex:idx_identity_provider_subject;not null" json:"-"`
+	Email     string    `json:"email"` // Email provider trả về lúc liên kết, chỉ để hiển thị
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ImpersonationLog đại diện cho bảng 'impersonation_logs': nhật ký từng request
+// mà admin thực hiện dưới danh nghĩa user khác (token có claim act)
+type ImpersonationLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ActorID   uint      `gorm:"index;not null" json:"actor_id"` // Admin thực sự gửi request
+	UserID    uint      `gorm:"index;not null" json:"user_id"`  // User đang được giả lập
+	SessionID uint      `json:"session_id"`                     // Phiên đăng nhập của admin
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Invitation đại diện cho bảng 'invitations': lời mời tạo tài khoản do admin gửi qua email.
+// Link trong email là JWT đã ký mang TokenID, gửi lại sẽ đổi TokenID nên link cũ hết hiệu lực
+type Invitation struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Email          string     `gorm:"index;not null" json:"email"`
+	Role           string     `gorm:"not null" json:"role,omitempty"`         // Role toàn hệ thống gán khi chấp nhận, rỗng với lời mời vào tổ chức
+	OrganizationID *uint      `gorm:"index" json:"organization_id,omitempty"` // Tổ chức mời (tự thêm vào làm thành viên)
+	OrgRole        string     `json:"org_role,omitempty"`
+	TokenID        string     `json:"-"`
+	InvitedBy      uint       `json:"invited_by"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	SentAt         time.Time  `json:"sent_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"-"`
+}
+
+// IsMembershipOnly: lời mời tham gia tổ chức dành cho tài khoản đã có (hoặc tự đăng ký bằng đúng email),
+// người nhận đăng nhập rồi chấp nhận qua POST /orgs/invitations/accept
+func (i *Invitation) IsMembershipOnly() bool {
+	return i.Role == ""
+}
+
+// IsPending kiểm tra lời mời chưa được chấp nhận và chưa bị thu hồi (có thể đã hết hạn, gửi lại sẽ gia hạn)
+func (i *Invitation) IsPending() bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil
+}
+
+// IsUsable kiểm tra link mời còn dùng được
+func (i *Invitation) IsUsable() bool {
+	return i.IsPending() && i.ExpiresAt.After(time.Now())
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Các phương thức đăng nhập được ghi vào lịch sử
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodTwoFactor = "2fa" // Bước nhập mã 2FA sau khi mật khẩu/OAuth/magic link đã đúng
+	LoginMethodOAuth     = "oauth"
+	LoginMethodMagicLink = "magic_link"
+)
+
+// LoginAttempt đại diện cho bảng 'login_attempts': lịch sử mọi lượt đăng nhập, thành công lẫn thất bại
+type LoginAttempt struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      *uint     `gorm:"index" json:"user_id"` // nil khi email không thuộc tài khoản nào
+	Email       string    `json:"email"`                // Email đã nhập (hoặc email của tài khoản)
+	Method      string    `json:"method"`               // password | 2fa | oauth:<provider> | magic_link
+	Success     bool      `json:"success"`
+	FailureCode string    `json:"failure_code,omitempty"` // Mã lỗi trả về cho client (VD: ERR_INVALID_CREDENTIALS)
+	IP          string    `json:"ip"`
+	IPRange     string    `gorm:"index" json:"-"` // Dải /24 (IPv4) hoặc /48 (IPv6), dùng để nhận biết thiết bị lạ
+	UserAgent   string    `json:"user_agent"`
+	NewDevice   bool      `json:"new_device"` // Đăng nhập thành công từ thiết bị hoặc dải IP chưa từng thấy
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
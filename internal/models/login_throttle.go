@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LoginThrottle đại diện cho bảng 'login_throttles': đếm số lần đăng nhập sai theo từng khoá.
+// Khoá "user:<id>" đếm theo tài khoản (để khoá tài khoản), "user:<id>|ip:<ip>" đếm theo cặp IP + tài khoản (để giãn thời gian thử)
+type LoginThrottle struct {
+	Key          string    `gorm:"primaryKey"`
+	UserID       uint      `gorm:"index;not null"`
+	Failures     int       `gorm:"not null;default:0"`
+	LastFailedAt time.Time `gorm:"not null"`
+	LockedUntil  *time.Time
+	UpdatedAt    time.Time
+}
+
+// IsLocked kiểm tra khoá đang trong thời gian bị khoá tạm thời
+func (t *LoginThrottle) IsLocked() bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(time.Now())
+}
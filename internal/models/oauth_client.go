@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// Các grant type mà authorization server hỗ trợ
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// Các scope chuẩn OpenID Connect, cấp cho ứng dụng đăng nhập một lần (SSO)
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopeOfflineAccess = "offline_access" // Cấp thêm refresh token
+)
+
+// OIDCScopes liệt kê các scope OpenID Connect. Client còn có thể xin các scope API trong AllScopes
+var OIDCScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeOfflineAccess}
+
+// OAuthClient đại diện cho bảng 'oauth_clients': ứng dụng khác đăng nhập một lần qua hệ thống này.
+// Client bí mật (confidential) chỉ lưu bản băm của secret; client công khai (SPA, mobile) không có secret
+// và bắt buộc dùng PKCE
+type OAuthClient struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ClientID     string     `gorm:"uniqueIndex;not null" json:"client_id"`
+	SecretHash   string     `json:"-"`
+	Name         string     `gorm:"not null" json:"name"`
+	RedirectURIs []string   `gorm:"serializer:json" json:"redirect_uris"`
+	Scopes       []string   `gorm:"serializer:json" json:"scopes"`
+	GrantTypes   []string   `gorm:"serializer:json" json:"grant_types"`
+	Public       bool       `json:"public"`
+	CreatedBy    uint       `json:"created_by"`
+	RevokedAt    *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// IsActive kiểm tra client chưa bị thu hồi
+func (c *OAuthClient) IsActive() bool {
+	return c.RevokedAt == nil
+}
+
+// AllowsGrant kiểm tra client được phép dùng grant type tương ứng
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return containsValue(c.GrantTypes, grantType)
+}
+
+// AllowsScope kiểm tra client được đăng ký với scope tương ứng
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	return containsValue(c.Scopes, scope)
+}
+
+// AllowsRedirect so khớp tuyệt đối redirect_uri với danh sách đã đăng ký (không so theo tiền tố)
+func (c *OAuthClient) AllowsRedirect(redirectURI string) bool {
+	return containsValue(c.RedirectURIs, redirectURI)
+}
+
+func containsValue(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthAuthorizationCode đại diện cho bảng 'oauth_authorization_codes': code ngắn hạn, dùng một lần,
+// chỉ lưu bản băm. Nếu code bị trình lại, phiên đã cấp từ nó bị thu hồi (RFC 6749 mục 4.1.2)
+type OAuthAuthorizationCode struct {
+	ID            uint   `gorm:"primaryKey"`
+	CodeHash      string `gorm:"uniqueIndex;not null"`
+	ClientID      string `gorm:"index;not null"`
+	UserID        uint   `gorm:"not null"`
+	RedirectURI   string `gorm:"not null"`
+	Scope         string
+	Nonce         string
+	CodeChallenge string `gorm:"not null"` // PKCE S256
+	AuthTime      time.Time
+	SessionID     *uint // Phiên được tạo khi đổi code lấy token
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Role của thành viên trong một tổ chức, độc lập với role toàn hệ thống (users.role)
+const (
+	OrgRoleOwner  = "owner"  // Toàn quyền, kể cả cấp/gỡ quyền owner
+	OrgRoleAdmin  = "admin"  // Quản lý thành viên
+	OrgRoleMember = "member" // Chỉ xem danh sách thành viên
+)
+
+// orgRoleRanks xếp hạng role trong tổ chức, role cao hơn bao gồm quyền của role thấp hơn
+var orgRoleRanks = map[string]int{
+	OrgRoleMember: 1,
+	OrgRoleAdmin:  2,
+	OrgRoleOwner:  3,
+}
+
+// IsValidOrgRole kiểm tra role có thuộc danh sách role của tổ chức
+func IsValidOrgRole(role string) bool {
+	_, ok := orgRoleRanks[role]
+	return ok
+}
+
+// OrgRoleAtLeast kiểm tra role có bằng hoặc cao hơn role tối thiểu
+func OrgRoleAtLeast(role, minimum string) bool {
+	return orgRoleRanks[role] >= orgRoleRanks[minimum] && orgRoleRanks[minimum] > 0
+}
+
+// Organization đại diện cho bảng 'organizations' (tenant của sản phẩm B2B)
+type Organization struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedBy uint      `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Membership đại diện cho bảng 'memberships': user thuộc tổ chức nào, với role gì
+type Membership struct {
+	ID             uint          `gorm:"primaryKey" json:"id"`
+	OrganizationID uint          `gorm:"uniqueIndex:idx_membership_org_user;not null" json:"organization_id"`
+	UserID         uint          `gorm:"uniqueIndex:idx_membership_org_user;index;not null" json:"user_id"`
+	Role           string        `gorm:"not null" json:"role"`
+	Organization   *Organization `json:"organization,omitempty"`
+	User           *User         `json:"user,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
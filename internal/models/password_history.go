@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PasswordHistory đại diện cho bảng 'password_histories': các hash mật khẩu user từng dùng,
+// để chính sách mật khẩu chặn việc đặt lại mật khẩu cũ
+type PasswordHistory struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	Hash      string `gorm:"not null"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Các scope có thể cấp cho Personal Access Token (PAT).
+// Token đăng nhập thường (JWT) không bị giới hạn bởi scope
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeUpload       = "upload"
+	ScopeUsersRead    = "users:read"  // Chỉ có tác dụng khi role của chủ token có quyền users:read
+	ScopeUsersWrite   = "users:write" // Chỉ có tác dụng khi role của chủ token có quyền quản trị user tương ứng
+)
+
+// AllScopes liệt kê các scope hợp lệ để validate khi tạo token
+var AllScopes = []string{ScopeProfileRead, ScopeProfileWrite, ScopeUpload, ScopeUsersRead, ScopeUsersWrite}
+
+// PersonalAccessToken đại diện cho bảng 'personal_access_tokens': API key dài hạn cho script/CI.
+// Token có dạng "pat_<prefix>_<secret>", DB chỉ lưu prefix (để tra cứu) và bản băm của toàn bộ token
+type PersonalAccessToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"uniqueIndex;not null" json:"prefix"`
+	TokenHash  string     `gorm:"not null" json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive kiểm tra token chưa bị thu hồi và chưa hết hạn
+func (t *PersonalAccessToken) IsActive() bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || t.ExpiresAt.After(time.Now()))
+}
+
+// HasScope kiểm tra token có được cấp scope tương ứng
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
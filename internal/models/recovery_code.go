@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RecoveryCode đại diện cho bảng 'recovery_codes': mã dự phòng dùng một lần khi mất thiết bị 2FA.
+// Chỉ lưu bản băm, mã gốc chỉ hiển thị cho user đúng một lần lúc tạo
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
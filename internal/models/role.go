@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Các quyền (permission) mà code kiểm tra qua middlewares.RequirePermission.
+// Danh sách được đồng bộ vào bảng 'permissions' mỗi lần khởi động, admin chỉ gán chúng vào role
+const (
+	PermDashboardView      = "dashboard:view"
+	PermUsersRead          = "users:read"
+	PermUsersWrite         = "users:write" // Sửa thông tin và gán role cho user
+	PermUsersDelete        = "users:delete"
+	PermUsersPurge         = "users:purge"
+	PermUsersUnlock        = "users:unlock"
+	PermUsersSuspend       = "users:suspend"
+	PermUsersInvite        = "users:invite"
+	PermUsersImpersonate   = "users:impersonate"
+	PermRolesManage        = "roles:manage"
+	PermOAuthClientsManage = "oauth_clients:manage"
+)
+
+// PermissionCatalog là danh mục quyền kèm mô tả để hiển thị trên trang quản trị
+var PermissionCatalog = []Permission{
+	{Name: PermDashboardView, Description: "Xem dashboard quản trị"},
+	{Name: PermUsersRead, Description: "Xem danh sách và chi tiết user"},
+	{Name: PermUsersWrite, Description: "Cập nhật user và gán role"},
+	{Name: PermUsersDelete, Description: "Xoá mềm user"},
+	{Name: PermUsersPurge, Description: "Xoá vĩnh viễn user"},
+	{Name: PermUsersUnlock, Description: "Mở khoá tài khoản bị khoá do đăng nhập sai"},
+	{Name: PermUsersSuspend, Description: "Đình chỉ và gỡ đình chỉ tài khoản"},
+	{Name: PermUsersInvite, Description: "Mời người dùng mới qua email"},
+	{Name: PermUsersImpersonate, Description: "Đăng nhập dưới danh nghĩa user khác để hỗ trợ (mọi request đều được ghi nhật ký)"},
+	{Name: PermRolesManage, Description: "Quản lý role và quyền"},
+	{Name: PermOAuthClientsManage, Description: "Quản lý ứng dụng đăng nhập qua hệ thống"},
+}
+
+// Permission đại diện cho bảng 'permissions'
+type Permission struct {
+	ID          uint   `gorm:"primaryKey" json:"-"`
+	Name        string `gorm:"uniqueIndex;not null" json:"name"`
+	Description string `json:"description"`
+}
+
+// Role đại diện cho bảng 'roles'. User tham chiếu role qua tên (users.role)
+type Role struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"uniqueIndex;not null" json:"name"`
+	Description string `json:"description"`
+	// System = true với các role dựng sẵn (admin, user): không xoá được
+	System bool `gorm:"default:false" json:"system"`
+	// PermissionVersion tăng mỗi khi danh sách quyền thay đổi, Access Token mang version cũ sẽ bị từ chối
+	PermissionVersion int          `gorm:"default:1" json:"permission_version"`
+	Permissions       []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// PermissionNames trả về tên các quyền của role (dùng cho JSON và claim của token)
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+// HasPermission kiểm tra role có quyền tương ứng
+func (r *Role) HasPermission(name string) bool {
+	for _, permission := range r.Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Session đại diện cho bảng 'sessions': mỗi thiết bị đăng nhập là một phiên riêng,
+// đồng thời là một "họ" (family) Refresh Token được xoay vòng liên tục
+type Session struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"index;not null" json:"-"`
+	FamilyID        string     `gorm:"index" json:"-"`
+	RefreshTokenID  string     `json:"-"`                                // jti của Refresh Token duy nhất còn hợp lệ trong họ
+	ClientID        string     `gorm:"index" json:"client_id,omitempty"` // OAuth client được cấp phiên (rỗng = đăng nhập trực tiếp)
+	OrganizationID  *uint      `json:"organization_id,omitempty"`        // Tổ chức đang làm việc (claim org_id của Access Token)
+	DeviceName      string     `json:"device_name"`
+	UserAgent       string     `json:"user_agent"`
+	IP              string     `json:"ip"`
+	LastUsedAt      time.Time  `json:"last_used_at"`
+	AuthenticatedAt time.Time  `json:"authenticated_at"` // Lần gần nhất user chứng minh thông tin đăng nhập (claim auth_time), refresh không làm mới
+	ExpiresAt       time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt       *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"-"`
+
+	Current bool `gorm:"-" json:"current"` // Đánh dấu phiên của request hiện tại (không lưu DB)
+}
+
+// IsActive kiểm tra phiên còn hiệu lực (chưa bị thu hồi và chưa hết hạn)
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
@@ -7,7 +7,7 @@ import (
 	"gorm.io/gorm"
 )
 
-// Định nghĩa các hằng số cho Role để tránh gõ sai chính tả (Hardcode string) ở nhiều nơi
+// Các role dựng sẵn, luôn tồn tại trong bảng 'roles'. Admin có thể tạo thêm role khác (VD: support)
 const (
 	RoleUser  = "user"
 	RoleAdmin = "admin"
@@ -17,15 +17,42 @@ const (
 type User struct {
 	ID                   uint           `gorm:"primaryKey" json:"id"`
 	Email                string         `gorm:"index:idx_email_unique,unique,where:deleted_at IS NULL;not null" json:"email"`
+	EmailVerifiedAt      *time.Time     `json:"email_verified_at"`
+	VerificationSentAt   *time.Time     `json:"-"`                       // Dùng để giới hạn tần suất gửi lại email xác thực
+	PendingEmail         *string        `json:"pending_email,omitempty"` // Email mới đang chờ xác nhận (chưa dùng để đăng nhập)
+	EmailChangeTokenID   *string        `json:"-"`                       // jti của link xác nhận gửi tới email mới
+	EmailRevertTokenID   *string        `json:"-"`                       // jti của link hoàn tác gửi tới email cũ
+	EmailRevertExpiresAt *time.Time     `json:"-"`
 	Password             string         `gorm:"not null" json:"-"` // Dấu - giúp ẩn field này khi trả về JSON
 	FullName             string         `json:"full_name"`
 	Avatar               string         `json:"avatar"`
-	Phone                string         `json:"phone"`
-	Role                 string         `gorm:"default:'user'" json:"role"`
+	Phone                string         `gorm:"index:idx_phone_verified_unique,unique,where:phone_verified_at IS NOT NULL AND deleted_at IS NULL" json:"phone"` // Dạng E.164
+	PhoneVerifiedAt      *time.Time     `json:"phone_verified_at"`
+	PhoneOTPHash         *string        `json:"-"` // Mã OTP xác thực số điện thoại đã băm
+	PhoneOTPAttempts     int            `gorm:"default:0" json:"-"`
+	PhoneOTPSentAt       *time.Time     `json:"-"`
+	PhoneOTPExpires      *time.Time     `json:"-"`
+	Role                 string         `gorm:"default:'user'" json:"role"` // Tên role trong bảng 'roles'
 	TokenVersion         int            `gorm:"default:1" json:"-"`
-	ResetPasswordOTP     *string        `gorm:"index;unique" json:"-"`
+	ResetOTPHash         *string        `json:"-"` // Mã OTP đặt lại mật khẩu đã băm, chỉ so khớp kèm email hoặc số điện thoại đã xác thực
+	ResetOTPAttempts     int            `gorm:"default:0" json:"-"`
+	ResetOTPSentAt       *time.Time     `json:"-"` // Dùng để giới hạn tần suất gửi lại mã
 	ResetPasswordExpires *time.Time     `json:"-"`
+	MagicLinkTokenID     *string        `json:"-"` // jti của link đăng nhập không mật khẩu gần nhất, xoá khi đã dùng
+	MagicLinkSentAt      *time.Time     `json:"-"`
+	TwoFactorSecret      *string        `json:"-"`
+	TwoFactorEnabledAt   *time.Time     `json:"two_factor_enabled_at"`
+	TwoFactorLastStep    int64          `json:"-"` // Time-step TOTP gần nhất đã dùng, chống replay mã
+	SuspendedAt          *time.Time     `json:"suspended_at,omitempty"`
+	SuspendedUntil       *time.Time     `json:"suspended_until,omitempty"` // nil = đình chỉ vô thời hạn
+	SuspendedBy          *uint          `json:"suspended_by,omitempty"`    // Admin thực hiện
+	SuspensionReason     string         `json:"suspension_reason,omitempty"`
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
 	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
 }
+
+// IsSuspended cho biết tài khoản đang bị đình chỉ. Hết SuspendedUntil thì tự động được gỡ, không cần tác vụ nền
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil && (u.SuspendedUntil == nil || u.SuspendedUntil.After(time.Now()))
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/cache"
+)
+
+// cachedOrganizationRepo bọc OrganizationRepository, lưu kết quả FindMembership vào cache để
+// RequireAuth không phải hỏi Postgres ở mỗi request mang org_id. Thêm, đổi role hay gỡ thành viên
+// đổi thế hệ cache của đúng cặp tổ chức + user, nên gỡ khỏi tổ chức vẫn có hiệu lực ngay
+type cachedOrganizationRepo struct {
+	inner OrganizationRepository
+	cache *generationCache
+}
+
+func NewCachedOrganizationRepository(inner OrganizationRepository, store cache.Store, ttl time.Duration) OrganizationRepository {
+	return &cachedOrganizationRepo{inner: inner, cache: &generationCache{store: store, ttl: ttl}}
+}
+
+func membershipCachePrefix(organizationID, userID uint) string {
+	return fmt.Sprintf("membership:%d:%d", organizationID, userID)
+}
+
+func (r *cachedOrganizationRepo) FindMembership(ctx context.Context, organizationID, userID uint) (*models.Membership, error) {
+	var membership models.Membership
+	var loaded *models.Membership
+	err := r.cache.fetch(ctx, membershipCachePrefix(organizationID, userID), "", &membership, func() error {
+		var err error
+		if loaded, err = r.inner.FindMembership(ctx, organizationID, userID); err == nil {
+			membership = *loaded
+		}
+		return err
+	})
+	if err != nil {
+		return loaded, err
+	}
+	return &membership, nil
+}
+
+func (r *cachedOrganizationRepo) Create(ctx context.Context, organization *models.Organization, owner *models.Membership) error {
+	return r.inner.Create(ctx, organization, owner)
+}
+
+func (r *cachedOrganizationRepo) FindByID(ctx context.Context, id uint) (*models.Organization, error) {
+	return r.inner.FindByID(ctx, id)
+}
+
+func (r *cachedOrganizationRepo) ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error) {
+	return r.inner.ListMembers(ctx, organizationID)
+}
+
+func (r *cachedOrganizationRepo) ListByUser(ctx context.Context, userID uint) ([]models.Membership, error) {
+	return r.inner.ListByUser(ctx, userID)
+}
+
+func (r *cachedOrganizationRepo) CountOwners(ctx context.Context, organizationID uint) (int64, error) {
+	return r.inner.CountOwners(ctx, organizationID)
+}
+
+func (r *cachedOrganizationRepo) AddMember(ctx context.Context, membership *models.Membership) error {
+	return r.cache.write(ctx, membershipCachePrefix(membership.OrganizationID, membership.UserID), func() error {
+		return r.inner.AddMember(ctx, membership)
+	})
+}
+
+func (r *cachedOrganizationRepo) UpdateMemberRole(ctx context.Context, membership *models.Membership) error {
+	return r.cache.write(ctx, membershipCachePrefix(membership.OrganizationID, membership.UserID), func() error {
+		return r.inner.UpdateMemberRole(ctx, membership)
+	})
+}
+
+func (r *cachedOrganizationRepo) RemoveMember(ctx context.Context, organizationID, userID uint) error {
+	return r.cache.write(ctx, membershipCachePrefix(organizationID, userID), func() error {
+		return r.inner.RemoveMember(ctx, organizationID, userID)
+	})
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/cache"
+)
+
+// fakeOrganizationRepo giữ membership theo cặp tổ chức + user và đếm số lần FindMembership chạm tới "database"
+type fakeOrganizationRepo struct {
+	memberships map[[2]uint]models.Membership
+	finds       int
+}
+
+func (r *fakeOrganizationRepo) FindMembership(_ context.Context, organizationID, userID uint) (*models.Membership, error) {
+	r.finds++
+	membership, ok := r.memberships[[2]uint{organizationID, userID}]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &membership, nil
+}
+
+func (r *fakeOrganizationRepo) AddMember(_ context.Context, membership *models.Membership) error {
+	r.memberships[[2]uint{membership.OrganizationID, membership.UserID}] = *membership
+	return nil
+}
+
+func (r *fakeOrganizationRepo) UpdateMemberRole(ctx context.Context, membership *models.Membership) error {
+	return r.AddMember(ctx, membership)
+}
+
+func (r *fakeOrganizationRepo) RemoveMember(_ context.Context, organizationID, userID uint) error {
+	delete(r.memberships, [2]uint{organizationID, userID})
+	return nil
+}
+
+func (r *fakeOrganizationRepo) Create(context.Context, *models.Organization, *models.Membership) error {
+	return nil
+}
+func (r *fakeOrganizationRepo) FindByID(context.Context, uint) (*models.Organization, error) {
+	return nil, errors.New("not found")
+}
+func (r *fakeOrganizationRepo) ListMembers(context.Context, uint) ([]models.Membership, error) {
+	return nil, nil
+}
+func (r *fakeOrganizationRepo) ListByUser(context.Context, uint) ([]models.Membership, error) {
+	return nil, nil
+}
+func (r *fakeOrganizationRepo) CountOwners(context.Context, uint) (int64, error) { return 0, nil }
+
+func TestCachedOrganizationRepoMembership(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeOrganizationRepo{memberships: make(map[[2]uint]models.Membership)}
+	repo := NewCachedOrganizationRepository(inner, cache.NewMemoryStore(10), time.Minute)
+
+	if err := repo.AddMember(ctx, &models.Membership{OrganizationID: 5, UserID: 1, Role: models.OrgRoleMember}); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		membership, err := repo.FindMembership(ctx, 5, 1)
+		if err != nil {
+			t.Fatalf("FindMembership: %v", err)
+		}
+		if membership.Role != models.OrgRoleMember {
+			t.Fatalf("role = %q, muốn member", membership.Role)
+		}
+	}
+	if inner.finds != 1 {
+		t.Fatalf("database bị đọc %d lần, muốn 1", inner.finds)
+	}
+
+	if err := repo.UpdateMemberRole(ctx, &models.Membership{OrganizationID: 5, UserID: 1, Role: models.OrgRoleAdmin}); err != nil {
+		t.Fatalf("UpdateMemberRole: %v", err)
+	}
+	if membership, err := repo.FindMembership(ctx, 5, 1); err != nil || membership.Role != models.OrgRoleAdmin {
+		t.Fatalf("đọc lại sau khi đổi role nhận %+v, %v", membership, err)
+	}
+
+	// Gỡ khỏi tổ chức phải có hiệu lực ngay, token mang org_id cũ bị từ chối ở request kế tiếp
+	if err := repo.RemoveMember(ctx, 5, 1); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if _, err := repo.FindMembership(ctx, 5, 1); err == nil {
+		t.Fatal("membership đã gỡ vẫn đọc được từ cache")
+	}
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/cache"
+)
+
+// roleCachePrefix: số role rất ít và hiếm khi đổi, nên mọi role dùng chung một thế hệ.
+// Thao tác ghi bất kỳ (kể cả đổi tên, xoá theo ID) vô hiệu toàn bộ mà không cần biết tên role
+const roleCachePrefix = "role"
+
+// cachedRoleRepo bọc RoleRepository, lưu kết quả FindByName (kèm danh sách quyền) vào cache
+// để RequireAuth không phải preload quyền của role ở mỗi request
+type cachedRoleRepo struct {
+	inner RoleRepository
+	cache *generationCache
+}
+
+func NewCachedRoleRepository(inner RoleRepository, store cache.Store, ttl time.Duration) RoleRepository {
+	return &cachedRoleRepo{inner: inner, cache: &generationCache{store: store, ttl: ttl}}
+}
+
+func (r *cachedRoleRepo) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	var loaded *models.Role
+	err := r.cache.fetch(ctx, roleCachePrefix, name, &role, func() error {
+		var err error
+		if loaded, err = r.inner.FindByName(ctx, name); err == nil {
+			role = *loaded
+		}
+		return err
+	})
+	if err != nil {
+		return loaded, err
+	}
+	return &role, nil
+}
+
+func (r *cachedRoleRepo) FindByID(ctx context.Context, id uint) (*models.Role, error) {
+	return r.inner.FindByID(ctx, id)
+}
+
+func (r *cachedRoleRepo) List(ctx context.Context) ([]models.Role, error) {
+	return r.inner.List(ctx)
+}
+
+func (r *cachedRoleRepo) CountUsers(ctx context.Context, name string) (int64, error) {
+	return r.inner.CountUsers(ctx, name)
+}
+
+func (r *cachedRoleRepo) FindPermissions(ctx context.Context, names []string) ([]models.Permission, error) {
+	return r.inner.FindPermissions(ctx, names)
+}
+
+func (r *cachedRoleRepo) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	return r.inner.ListPermissions(ctx)
+}
+
+func (r *cachedRoleRepo) Create(ctx context.Context, role *models.Role) error {
+	return r.cache.write(ctx, roleCachePrefix, func() error {
+		return r.inner.Create(ctx, role)
+	})
+}
+
+func (r *cachedRoleRepo) Update(ctx context.Context, role *models.Role) error {
+	return r.cache.write(ctx, roleCachePrefix, func() error {
+		return r.inner.Update(ctx, role)
+	})
+}
+
+func (r *cachedRoleRepo) Delete(ctx context.Context, id uint) error {
+	return r.cache.write(ctx, roleCachePrefix, func() error {
+		return r.inner.Delete(ctx, id)
+	})
+}
+
+// SyncPermissions đổi mô tả của quyền đang nằm trong role đã cache
+func (r *cachedRoleRepo) SyncPermissions(ctx context.Context, catalog []models.Permission) error {
+	return r.cache.write(ctx, roleCachePrefix, func() error {
+		return r.inner.SyncPermissions(ctx, catalog)
+	})
+}
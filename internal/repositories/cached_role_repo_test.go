@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/cache"
+)
+
+// fakeRoleRepo giữ role trong map theo tên và đếm số lần FindByName chạm tới "database"
+type fakeRoleRepo struct {
+	roles map[string]models.Role
+	finds int
+}
+
+func (r *fakeRoleRepo) FindByName(_ context.Context, name string) (*models.Role, error) {
+	r.finds++
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &role, nil
+}
+
+func (r *fakeRoleRepo) Update(_ context.Context, role *models.Role) error {
+	r.roles[role.Name] = *role
+	return nil
+}
+
+func (r *fakeRoleRepo) Delete(_ context.Context, id uint) error {
+	for name, role := range r.roles {
+		if role.ID == id {
+			delete(r.roles, name)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRoleRepo) Create(context.Context, *models.Role) error { return nil }
+func (r *fakeRoleRepo) FindByID(context.Context, uint) (*models.Role, error) {
+	return nil, errors.New("not found")
+}
+func (r *fakeRoleRepo) List(context.Context) ([]models.Role, error)       { return nil, nil }
+func (r *fakeRoleRepo) CountUsers(context.Context, string) (int64, error) { return 0, nil }
+func (r *fakeRoleRepo) SyncPermissions(context.Context, []models.Permission) error {
+	return nil
+}
+func (r *fakeRoleRepo) FindPermissions(context.Context, []string) ([]models.Permission, error) {
+	return nil, nil
+}
+func (r *fakeRoleRepo) ListPermissions(context.Context) ([]models.Permission, error) {
+	return nil, nil
+}
+
+func TestCachedRoleRepoFindByNameUsesCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeRoleRepo{roles: map[string]models.Role{
+		"support": {ID: 2, Name: "support", PermissionVersion: 1, Permissions: []models.Permission{{ID: 1, Name: models.PermUsersRead}}},
+	}}
+	store := cache.NewMemoryStore(10)
+	repo := NewCachedRoleRepository(inner, store, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		role, err := repo.FindByName(ctx, "support")
+		if err != nil {
+			t.Fatalf("FindByName: %v", err)
+		}
+		if !role.HasPermission(models.PermUsersRead) {
+			t.Fatalf("role từ cache mất danh sách quyền: %+v", role)
+		}
+	}
+	if inner.finds != 1 {
+		t.Fatalf("database bị đọc %d lần, muốn 1", inner.finds)
+	}
+
+	// Đổi quyền làm tăng PermissionVersion: RequireAuth phải thấy ngay version mới
+	if err := repo.Update(ctx, &models.Role{ID: 2, Name: "support", PermissionVersion: 2}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	role, err := repo.FindByName(ctx, "support")
+	if err != nil {
+		t.Fatalf("FindByName: %v", err)
+	}
+	if role.PermissionVersion != 2 {
+		t.Fatalf("PermissionVersion = %d, cache cũ chưa bị vô hiệu", role.PermissionVersion)
+	}
+
+	// Xoá theo ID vẫn vô hiệu entry tra cứu theo tên
+	if err := repo.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.FindByName(ctx, "support"); err == nil {
+		t.Fatal("role đã xoá vẫn đọc được từ cache")
+	}
+}
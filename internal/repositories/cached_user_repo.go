@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/cache"
+	"go-core-api/pkg/utils"
+)
+
+// cachedUserRepo bọc UserRepository, lưu kết quả FindByID vào cache để RequireAuth không phải
+// hỏi Postgres ở mỗi request. Mọi thao tác ghi lên user đều đổi thế hệ cache của user đó, nhờ vậy
+// đổi mật khẩu / thu hồi token (TokenVersion tăng) vẫn có hiệu lực ngay lập tức
+type cachedUserRepo struct {
+	inner UserRepository
+	cache *generationCache
+}
+
+func NewCachedUserRepository(inner UserRepository, store cache.Store, ttl time.Duration) UserRepository {
+	return &cachedUserRepo{inner: inner, cache: &generationCache{store: store, ttl: ttl}}
+}
+
+func userCachePrefix(id uint) string {
+	return fmt.Sprintf("user:%d", id)
+}
+
+// FindByID chỉ dùng cache cho tra cứu toàn hệ thống. Tra cứu trong một tenant còn phụ thuộc
+// quyền thành viên (không đi qua repository này) nên luôn đọc thẳng từ database
+func (r *cachedUserRepo) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	if _, scoped := TenantFromContext(ctx); scoped {
+		return r.inner.FindByID(ctx, id)
+	}
+
+	var user models.User
+	var loaded *models.User
+	err := r.cache.fetch(ctx, userCachePrefix(id), "", &user, func() error {
+		var err error
+		if loaded, err = r.inner.FindByID(ctx, id); err == nil {
+			user = *loaded
+		}
+		return err
+	})
+	if err != nil {
+		return loaded, err
+	}
+	return &user, nil
+}
+
+func (r *cachedUserRepo) write(ctx context.Context, id uint, fn func() error) error {
+	return r.cache.write(ctx, userCachePrefix(id), fn)
+}
+
+func (r *cachedUserRepo) Create(ctx context.Context, user *models.User) error {
+	return r.inner.Create(ctx, user)
+}
+
+func (r *cachedUserRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.inner.FindByEmail(ctx, email)
+}
+
+func (r *cachedUserRepo) FindByVerifiedPhone(ctx context.Context, phone string) (*models.User, error) {
+	return r.inner.FindByVerifiedPhone(ctx, phone)
+}
+
+func (r *cachedUserRepo) GetList(ctx context.Context, pagination utils.Pagination) ([]models.User, int64, error) {
+	return r.inner.GetList(ctx, pagination)
+}
+
+func (r *cachedUserRepo) Update(ctx context.Context, user *models.User) error {
+	return r.write(ctx, user.ID, func() error {
+		return r.inner.Update(ctx, user)
+	})
+}
+
+func (r *cachedUserRepo) Delete(ctx context.Context, id uint) error {
+	return r.write(ctx, id, func() error {
+		return r.inner.Delete(ctx, id)
+	})
+}
+
+func (r *cachedUserRepo) Purge(ctx context.Context, id uint) error {
+	return r.write(ctx, id, func() error {
+		return r.inner.Purge(ctx, id)
+	})
+}
+
+func (r *cachedUserRepo) ConsumeResetAttempt(ctx context.Context, id uint, maxAttempts int) (allowed bool, err error) {
+	err = r.write(ctx, id, func() error {
+		allowed, err = r.inner.ConsumeResetAttempt(ctx, id, maxAttempts)
+		return err
+	})
+	return allowed, err
+}
+
+func (r *cachedUserRepo) ConsumePhoneOTPAttempt(ctx context.Context, id uint, maxAttempts int) (allowed bool, err error) {
+	err = r.write(ctx, id, func() error {
+		allowed, err = r.inner.ConsumePhoneOTPAttempt(ctx, id, maxAttempts)
+		return err
+	})
+	return allowed, err
+}
+
+func (r *cachedUserRepo) ClaimMagicLink(ctx context.Context, id uint, tokenID string) (claimed bool, err error) {
+	err = r.write(ctx, id, func() error {
+		claimed, err = r.inner.ClaimMagicLink(ctx, id, tokenID)
+		return err
+	})
+	return claimed, err
+}
+
+func (r *cachedUserRepo) ClaimTOTPStep(ctx context.Context, id uint, step int64) (claimed bool, err error) {
+	err = r.write(ctx, id, func() error {
+		claimed, err = r.inner.ClaimTOTPStep(ctx, id, step)
+		return err
+	})
+	return claimed, err
+}
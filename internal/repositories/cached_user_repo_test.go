@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/cache"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeUserRepo giữ user trong map và đếm số lần FindByID chạm tới "database"
+type fakeUserRepo struct {
+	users    map[uint]models.User
+	finds    int
+	writeErr error
+	onWrite  func() // Chạy giữa thao tác ghi, giả lập request đọc song song
+	onFind   func() // Chạy sau khi đã đọc xong dữ liệu, giả lập request đọc chậm
+}
+
+func newFakeUserRepo(users ...models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: make(map[uint]models.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) FindByID(_ context.Context, id uint) (*models.User, error) {
+	r.finds++
+	user, ok := r.users[id]
+	if r.onFind != nil {
+		r.onFind()
+	}
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &user, nil
+}
+
+func (r *fakeUserRepo) write(id uint, apply func(user *models.User)) error {
+	if r.writeErr != nil {
+		return r.writeErr
+	}
+	user := r.users[id]
+	apply(&user)
+	if r.onWrite != nil {
+		r.onWrite()
+	}
+	r.users[id] = user
+	return nil
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, user *models.User) error {
+	return r.write(user.ID, func(stored *models.User) { *stored = *user })
+}
+
+func (r *fakeUserRepo) Delete(_ context.Context, id uint) error {
+	if r.writeErr != nil {
+		return r.writeErr
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) Purge(ctx context.Context, id uint) error {
+	return r.Delete(ctx, id)
+}
+
+func (r *fakeUserRepo) ConsumeResetAttempt(_ context.Context, id uint, _ int) (bool, error) {
+	return true, r.write(id, func(user *models.User) { user.TokenVersion++ })
+}
+
+func (r *fakeUserRepo) ConsumePhoneOTPAttempt(_ context.Context, id uint, _ int) (bool, error) {
+	return true, r.write(id, func(user *models.User) { user.TokenVersion++ })
+}
+
+func (r *fakeUserRepo) ClaimMagicLink(_ context.Context, id uint, _ string) (bool, error) {
+	return true, r.write(id, func(user *models.User) { user.TokenVersion++ })
+}
+
+func (r *fakeUserRepo) ClaimTOTPStep(_ context.Context, id uint, step int64) (bool, error) {
+	return true, r.write(id, func(user *models.User) { user.TwoFactorLastStep = step })
+}
+
+func (r *fakeUserRepo) Create(context.Context, *models.User) error { return nil }
+func (r *fakeUserRepo) FindByEmail(context.Context, string) (*models.User, error) {
+	return nil, errors.New("not found")
+}
+func (r *fakeUserRepo) FindByVerifiedPhone(context.Context, string) (*models.User, error) {
+	return nil, errors.New("not found")
+}
+func (r *fakeUserRepo) GetList(context.Context, utils.Pagination) ([]models.User, int64, error) {
+	return nil, 0, nil
+}
+
+// flakyStore bọc memory store, cho phép làm hỏng Set để kiểm tra lỗi được trả về
+type flakyStore struct {
+	cache.Store
+	setErr error
+	bumps  int // Số lần đổi thế hệ của user
+}
+
+func (s *flakyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if strings.HasSuffix(key, ":gen") {
+		s.bumps++
+	}
+	if s.setErr != nil {
+		return s.setErr
+	}
+	return s.Store.Set(ctx, key, value, ttl)
+}
+
+func newCachedRepo(inner UserRepository) (UserRepository, *flakyStore) {
+	store := &flakyStore{Store: cache.NewMemoryStore(10)}
+	return NewCachedUserRepository(inner, store, time.Minute), store
+}
+
+func TestCachedUserRepoFindByIDUsesCache(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeUserRepo(models.User{ID: 1, Email: "a@example.com", Password: "hash", TokenVersion: 3})
+	repo, _ := newCachedRepo(inner)
+
+	for i := 0; i < 3; i++ {
+		user, err := repo.FindByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		// Field ẩn khỏi JSON vẫn phải có trong bản lưu cache
+		if user.Password != "hash" || user.TokenVersion != 3 {
+			t.Fatalf("user từ cache thiếu dữ liệu: %+v", user)
+		}
+		user.TokenVersion = 99 // Sửa bản trả về không được làm bẩn cache
+	}
+	if inner.finds != 1 {
+		t.Fatalf("database bị đọc %d lần, muốn 1", inner.finds)
+	}
+}
+
+func TestCachedUserRepoSkipsCacheInTenant(t *testing.T) {
+	inner := newFakeUserRepo(models.User{ID: 1})
+	repo, _ := newCachedRepo(inner)
+
+	ctx := WithTenant(context.Background(), 5)
+	for i := 0; i < 2; i++ {
+		if _, err := repo.FindByID(ctx, 1); err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+	}
+	if inner.finds != 2 {
+		t.Fatalf("tra cứu trong tenant phải luôn đọc database, đọc %d lần", inner.finds)
+	}
+}
+
+func TestCachedUserRepoWritesInvalidate(t *testing.T) {
+	writes := map[string]func(ctx context.Context, repo UserRepository) error{
+		"Update": func(ctx context.Context, repo UserRepository) error {
+			return repo.Update(ctx, &models.User{ID: 1, TokenVersion: 2})
+		},
+		"ConsumeResetAttempt": func(ctx context.Context, repo UserRepository) error {
+			_, err := repo.ConsumeResetAttempt(ctx, 1, 5)
+			return err
+		},
+		"ConsumePhoneOTPAttempt": func(ctx context.Context, repo UserRepository) error {
+			_, err := repo.ConsumePhoneOTPAttempt(ctx, 1, 5)
+			return err
+		},
+		"ClaimMagicLink": func(ctx context.Context, repo UserRepository) error {
+			_, err := repo.ClaimMagicLink(ctx, 1, "jti")
+			return err
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inner := newFakeUserRepo(models.User{ID: 1, TokenVersion: 1})
+			repo, _ := newCachedRepo(inner)
+
+			if _, err := repo.FindByID(ctx, 1); err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			if err := write(ctx, repo); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			user, err := repo.FindByID(ctx, 1)
+			if err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			if user.TokenVersion != 2 {
+				t.Fatalf("đọc lại sau %s nhận TokenVersion = %d, cache cũ chưa bị vô hiệu", name, user.TokenVersion)
+			}
+		})
+	}
+}
+
+func TestCachedUserRepoDeleteInvalidates(t *testing.T) {
+	for _, purge := range []bool{false, true} {
+		ctx := context.Background()
+		inner := newFakeUserRepo(models.User{ID: 1})
+		repo, _ := newCachedRepo(inner)
+
+		if _, err := repo.FindByID(ctx, 1); err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		var err error
+		if purge {
+			err = repo.Purge(ctx, 1)
+		} else {
+			err = repo.Delete(ctx, 1)
+		}
+		if err != nil {
+			t.Fatalf("xoá user: %v", err)
+		}
+		if _, err := repo.FindByID(ctx, 1); err == nil {
+			t.Fatalf("user đã xoá (purge = %v) vẫn đọc được từ cache", purge)
+		}
+	}
+}
+
+func TestCachedUserRepoInvalidatesAfterConcurrentRead(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeUserRepo(models.User{ID: 1, TokenVersion: 1})
+	repo, _ := newCachedRepo(inner)
+
+	// Request khác đọc và nạp lại cache trong lúc thao tác ghi chưa xong
+	inner.onWrite = func() {
+		inner.onWrite = nil
+		if _, err := repo.FindByID(ctx, 1); err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+	}
+	if err := repo.Update(ctx, &models.User{ID: 1, TokenVersion: 2}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	user, err := repo.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if user.TokenVersion != 2 {
+		t.Fatalf("TokenVersion = %d, entry nạp lại trong lúc ghi không được phục vụ", user.TokenVersion)
+	}
+}
+
+// Request đọc chậm lấy bản cũ từ database, thao tác ghi chạy xong trọn vẹn rồi request đọc mới ghi cache
+func TestCachedUserRepoSlowReaderDoesNotRestoreStaleEntry(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeUserRepo(models.User{ID: 1, TokenVersion: 1})
+	repo, _ := newCachedRepo(inner)
+
+	readDone := make(chan struct{})
+	writeDone := make(chan struct{})
+	inner.onFind = func() {
+		inner.onFind = nil
+		close(readDone)
+		<-writeDone
+	}
+
+	readerDone := make(chan *models.User)
+	go func() {
+		user, err := repo.FindByID(ctx, 1)
+		if err != nil {
+			t.Errorf("FindByID: %v", err)
+		}
+		readerDone <- user
+	}()
+
+	<-readDone
+	if err := repo.Update(ctx, &models.User{ID: 1, TokenVersion: 2}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	close(writeDone)
+	if stale := <-readerDone; stale == nil || stale.TokenVersion != 1 {
+		t.Fatalf("request đọc chậm phải nhận bản cũ, nhận %+v", stale)
+	}
+
+	user, err := repo.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if user.TokenVersion != 2 {
+		t.Fatalf("TokenVersion = %d, bản cũ do request đọc chậm ghi vào cache vẫn được phục vụ", user.TokenVersion)
+	}
+}
+
+func TestCachedUserRepoReturnsCacheError(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeUserRepo(models.User{ID: 1, TokenVersion: 1})
+	repo, store := newCachedRepo(inner)
+
+	storeErr := errors.New("redis down")
+	store.setErr = storeErr
+	if err := repo.Update(ctx, &models.User{ID: 1, TokenVersion: 2}); !errors.Is(err, storeErr) {
+		t.Fatalf("Update khi không vô hiệu được cache trả về %v, muốn %v", err, storeErr)
+	}
+	// Không vô hiệu được cache trước khi ghi thì không ghi, tránh database và cache lệch nhau
+	if inner.users[1].TokenVersion != 1 {
+		t.Fatal("không được ghi khi chưa vô hiệu được cache")
+	}
+	if _, err := repo.ClaimMagicLink(ctx, 1, "jti"); !errors.Is(err, storeErr) {
+		t.Fatalf("ClaimMagicLink trả về %v, muốn %v", err, storeErr)
+	}
+}
+
+func TestCachedUserRepoWriteErrorStillInvalidates(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeUserRepo(models.User{ID: 1})
+	repo, store := newCachedRepo(inner)
+
+	writeErr := errors.New("db down")
+	inner.writeErr = writeErr
+	if err := repo.Update(ctx, &models.User{ID: 1}); !errors.Is(err, writeErr) {
+		t.Fatalf("Update trả về %v, muốn lỗi của database", err)
+	}
+	if store.bumps != 2 {
+		t.Fatalf("thế hệ cache đổi %d lần, muốn đổi trước và sau khi ghi", store.bumps)
+	}
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"time"
+
+	"go-core-api/pkg/cache"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+const cacheGenerationAlphabet = "0123456789abcdef"
+
+// generationCache lưu bản gob của bản ghi dưới key gắn thế hệ (generation): "<prefix>:<thế hệ>[:<name>]",
+// thế hệ hiện tại nằm ở "<prefix>:gen". Thao tác ghi đổi sang thế hệ ngẫu nhiên chưa từng dùng trước và sau khi ghi,
+// nên request đọc chậm nạp lại dữ liệu cũ chỉ ghi vào key của thế hệ cũ mà không ai đọc nữa.
+// Dùng chung cho các repository có cache (user, role, membership)
+type generationCache struct {
+	store cache.Store
+	ttl   time.Duration
+}
+
+func generationKey(prefix string) string {
+	return prefix + ":gen"
+}
+
+// current trả về thế hệ hiện tại, tạo thế hệ mới khi chưa có (lần đầu hoặc đã hết hạn)
+func (c *generationCache) current(ctx context.Context, prefix string) (string, error) {
+	generation, found, err := c.store.Get(ctx, generationKey(prefix))
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return string(generation), nil
+	}
+	return c.bump(ctx, prefix)
+}
+
+// bump chuyển sang thế hệ mới. Entry của thế hệ cũ tự hết hạn sau TTL; thế hệ sống lâu hơn entry
+// để cache không bị làm nguội vô ích
+func (c *generationCache) bump(ctx context.Context, prefix string) (string, error) {
+	generation, err := utils.GenerateRandomString(16, cacheGenerationAlphabet)
+	if err != nil {
+		return "", err
+	}
+	if err := c.store.Set(ctx, generationKey(prefix), []byte(generation), 10*c.ttl); err != nil {
+		return "", err
+	}
+	return generation, nil
+}
+
+// fetch đọc entry thuộc thế hệ hiện tại vào value (con trỏ). Cache miss thì gọi load để đọc database
+// và điền vào value; load trả lỗi thì không lưu gì. Lỗi của cache chỉ được ghi log.
+// Thế hệ được đọc trước khi hỏi database, đó là điều giữ cho entry cũ không sống lại
+func (c *generationCache) fetch(ctx context.Context, prefix, name string, value interface{}, load func() error) error {
+	generation, err := c.current(ctx, prefix)
+	if err != nil {
+		logger.Warn("Không thể đọc thế hệ cache", zap.String("key", prefix), zap.Error(err))
+		return load()
+	}
+
+	key := prefix + ":" + generation
+	if name != "" {
+		key += ":" + name
+	}
+	if data, found, err := c.store.Get(ctx, key); err != nil {
+		logger.Warn("Không thể đọc cache", zap.String("key", key), zap.Error(err))
+	} else if found {
+		// Giải mã ra bản sao mới: nơi gọi sửa bản ghi cũng không làm bẩn cache
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(value); err == nil {
+			return nil
+		}
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+
+	// Dùng gob thay vì JSON vì các field như Password, TokenVersion bị ẩn khỏi JSON
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		logger.Warn("Không thể mã hoá bản ghi để lưu cache", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	if err := c.store.Set(ctx, key, buf.Bytes(), c.ttl); err != nil {
+		logger.Warn("Không thể ghi cache", zap.String("key", key), zap.Error(err))
+	}
+	return nil
+}
+
+// write đổi thế hệ trước và sau thao tác ghi. Đổi trước để không ghi khi không vô hiệu được cache;
+// đổi sau để mọi entry nạp từ dữ liệu cũ (kể cả của request đọc song song) không còn được đọc tới.
+// Lỗi của cache được trả về để thao tác nhạy cảm (thu hồi token, khoá tài khoản) không âm thầm chậm hiệu lực
+func (c *generationCache) write(ctx context.Context, prefix string, fn func() error) error {
+	if _, err := c.bump(ctx, prefix); err != nil {
+		logger.Error("Không thể vô hiệu cache trước khi ghi", zap.String("key", prefix), zap.Error(err))
+		return err
+	}
+
+	writeErr := fn()
+	if _, err := c.bump(ctx, prefix); err != nil {
+		logger.Error("Không thể vô hiệu cache sau khi ghi", zap.String("key", prefix), zap.Error(err))
+		if writeErr == nil {
+			return err
+		}
+	}
+	return writeErr
+}
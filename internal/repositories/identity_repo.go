@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *models.Identity) error
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error)
+}
+
+type identityRepo struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepo{db: db}
+}
+
+func (r *identityRepo) Create(ctx context.Context, identity *models.Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *identityRepo) FindByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	var identity models.Identity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	return &identity, err
+}
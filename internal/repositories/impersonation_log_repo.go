@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ImpersonationLogRepository interface {
+	Create(ctx context.Context, entry *models.ImpersonationLog) error
+}
+
+type impersonationLogRepo struct {
+	db *gorm.DB
+}
+
+func NewImpersonationLogRepository(db *gorm.DB) ImpersonationLogRepository {
+	return &impersonationLogRepo{db: db}
+}
+
+func (r *impersonationLogRepo) Create(ctx context.Context, entry *models.ImpersonationLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
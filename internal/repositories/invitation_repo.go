@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.Invitation) error
+	FindByID(ctx context.Context, id uint) (*models.Invitation, error)
+	FindPendingByEmail(ctx context.Context, email string) (*models.Invitation, error)
+	FindPendingMembership(ctx context.Context, organizationID uint, email string) (*models.Invitation, error)
+	ListPending(ctx context.Context) ([]models.Invitation, error)
+	Update(ctx context.Context, invitation *models.Invitation) error
+}
+
+type invitationRepo struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepo{db: db}
+}
+
+// tenantInvitations giới hạn trong lời mời của tổ chức đang làm việc (nếu có)
+func tenantInvitations(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationID, ok := TenantFromContext(ctx); ok {
+			return db.Where("organization_id = ?", organizationID)
+		}
+		return db
+	}
+}
+
+func (r *invitationRepo) Create(ctx context.Context, invitation *models.Invitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+// FindByID tra theo phạm vi tenant, dùng cho thao tác quản trị (gửi lại, thu hồi)
+func (r *invitationRepo) FindByID(ctx context.Context, id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).Scopes(tenantInvitations(ctx)).First(&invitation, id).Error
+	return &invitation, err
+}
+
+// FindPendingByEmail tìm lời mời tạo tài khoản đang chờ của email trên toàn hệ thống
+func (r *invitationRepo) FindPendingByEmail(ctx context.Context, email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).
+		Where("email = ? AND role <> '' AND accepted_at IS NULL AND revoked_at IS NULL", email).
+		First(&invitation).Error
+	return &invitation, err
+}
+
+// FindPendingMembership tìm lời mời tham gia tổ chức đang chờ của email
+func (r *invitationRepo) FindPendingMembership(ctx context.Context, organizationID uint, email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND email = ? AND role = '' AND accepted_at IS NULL AND revoked_at IS NULL", organizationID, email).
+		First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *invitationRepo) ListPending(ctx context.Context) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	err := r.db.WithContext(ctx).
+		Scopes(tenantInvitations(ctx)).
+		Where("role <> '' AND accepted_at IS NULL AND revoked_at IS NULL").
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepo) Update(ctx context.Context, invitation *models.Invitation) error {
+	return r.db.WithContext(ctx).Save(invitation).Error
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type LoginHistoryRepository interface {
+	Create(ctx context.Context, entry *models.LoginAttempt) error
+	ListByUser(ctx context.Context, userID uint, pagination utils.Pagination) ([]models.LoginAttempt, int64, error)
+	HasSuccess(ctx context.Context, userID uint, userAgent, ipRange string) (bool, error)
+}
+
+type loginHistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginHistoryRepository(db *gorm.DB) LoginHistoryRepository {
+	return &loginHistoryRepo{db: db}
+}
+
+func (r *loginHistoryRepo) Create(ctx context.Context, entry *models.LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// ListByUser luôn sắp xếp mới nhất trước (bỏ qua sort/keyword của pagination)
+func (r *loginHistoryRepo) ListByUser(ctx context.Context, userID uint, pagination utils.Pagination) ([]models.LoginAttempt, int64, error) {
+	var attempts []models.LoginAttempt
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.LoginAttempt{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(pagination.Limit).
+		Offset(pagination.GetOffSet()).
+		Order("created_at desc, id desc").
+		Find(&attempts).Error
+
+	return attempts, total, err
+}
+
+// HasSuccess cho biết user đã từng đăng nhập thành công với user agent / dải IP này chưa (bỏ trống = không lọc)
+func (r *loginHistoryRepo) HasSuccess(ctx context.Context, userID uint, userAgent, ipRange string) (bool, error) {
+	query := r.db.WithContext(ctx).Model(&models.LoginAttempt{}).Where("user_id = ? AND success = ?", userID, true)
+	if userAgent != "" {
+		query = query.Where("user_agent = ?", userAgent)
+	}
+	if ipRange != "" {
+		query = query.Where("ip_range = ?", ipRange)
+	}
+
+	var found []uint
+	if err := query.Limit(1).Pluck("id", &found).Error; err != nil {
+		return false, err
+	}
+	return len(found) > 0, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginThrottleRepository interface {
+	Find(ctx context.Context, key string) (*models.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, userID uint, window time.Duration) (*models.LoginThrottle, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, keys ...string) error
+	ResetByUser(ctx context.Context, userID uint) error
+}
+
+type loginThrottleRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepo{db: db}
+}
+
+func (r *loginThrottleRepo) Find(ctx context.Context, key string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&throttle).Error
+	return &throttle, err
+}
+
+// RecordFailure tăng bộ đếm một cách nguyên tử (UPSERT) để nhiều request song song không đếm sót.
+// Lần sai trước đã quá cũ (ngoài window) thì đếm lại từ 1
+func (r *loginThrottleRepo) RecordFailure(ctx context.Context, key string, userID uint, window time.Duration) (*models.LoginThrottle, error) {
+	now := time.Now()
+	throttle := models.LoginThrottle{
+		Key:          key,
+		UserID:       userID,
+		Failures:     1,
+		LastFailedAt: now,
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":       gorm.Expr("CASE WHEN login_throttles.last_failed_at < ? THEN 1 ELSE login_throttles.failures + 1 END", now.Add(-window)),
+			"last_failed_at": now,
+			"updated_at":     now,
+		}),
+	}).Create(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Find(ctx, key)
+}
+
+// Lock khoá tạm thời và đặt lại bộ đếm, để khi hết hạn khoá user có lại lượt thử mới
+func (r *loginThrottleRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.LoginThrottle{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{"locked_until": until, "failures": 0}).Error
+}
+
+func (r *loginThrottleRepo) Reset(ctx context.Context, keys ...string) error {
+	return r.db.WithContext(ctx).Where("key IN ?", keys).Delete(&models.LoginThrottle{}).Error
+}
+
+func (r *loginThrottleRepo) ResetByUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.LoginThrottle{}).Error
+}
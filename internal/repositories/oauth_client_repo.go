@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *models.OAuthClient) error
+	FindByID(ctx context.Context, id uint) (*models.OAuthClient, error)
+	FindByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	ListActive(ctx context.Context) ([]models.OAuthClient, error)
+	Revoke(ctx context.Context, id uint) (bool, error)
+}
+
+type oauthClientRepo struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepo{db: db}
+}
+
+func (r *oauthClientRepo) Create(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+func (r *oauthClientRepo) FindByID(ctx context.Context, id uint) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).First(&client, id).Error
+	return &client, err
+}
+
+func (r *oauthClientRepo) FindByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error
+	return &client, err
+}
+
+func (r *oauthClientRepo) ListActive(ctx context.Context) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	err := r.db.WithContext(ctx).
+		Where("revoked_at IS NULL").
+		Order("created_at DESC").
+		Find(&clients).Error
+	return clients, err
+}
+
+// Revoke trả về false nếu client không tồn tại hoặc đã bị thu hồi trước đó
+func (r *oauthClientRepo) Revoke(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OAuthClient{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
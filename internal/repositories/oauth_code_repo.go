@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OAuthCodeRepository interface {
+	Create(ctx context.Context, code *models.OAuthAuthorizationCode) error
+	FindByHash(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error)
+	MarkUsed(ctx context.Context, id uint, sessionID uint) (bool, error)
+}
+
+type oauthCodeRepo struct {
+	db *gorm.DB
+}
+
+func NewOAuthCodeRepository(db *gorm.DB) OAuthCodeRepository {
+	return &oauthCodeRepo{db: db}
+}
+
+func (r *oauthCodeRepo) Create(ctx context.Context, code *models.OAuthAuthorizationCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+func (r *oauthCodeRepo) FindByHash(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	err := r.db.WithContext(ctx).Where("code_hash = ?", codeHash).First(&code).Error
+	return &code, err
+}
+
+// MarkUsed đánh dấu code đã đổi và ghi lại phiên được cấp, trả về false nếu một request khác đã dùng code trước
+func (r *oauthCodeRepo) MarkUsed(ctx context.Context, id uint, sessionID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Updates(map[string]interface{}{"used_at": time.Now(), "session_id": sessionID})
+	return result.RowsAffected == 1, result.Error
+}
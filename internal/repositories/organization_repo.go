@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *models.Organization, owner *models.Membership) error
+	FindByID(ctx context.Context, id uint) (*models.Organization, error)
+	AddMember(ctx context.Context, membership *models.Membership) error
+	FindMembership(ctx context.Context, organizationID, userID uint) (*models.Membership, error)
+	ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error)
+	ListByUser(ctx context.Context, userID uint) ([]models.Membership, error)
+	UpdateMemberRole(ctx context.Context, membership *models.Membership) error
+	RemoveMember(ctx context.Context, organizationID, userID uint) error
+	CountOwners(ctx context.Context, organizationID uint) (int64, error)
+}
+
+type organizationRepo struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepo{db: db}
+}
+
+// Create tạo tổ chức và membership owner của người tạo trong cùng một transaction
+func (r *organizationRepo) Create(ctx context.Context, organization *models.Organization, owner *models.Membership) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		owner.OrganizationID = organization.ID
+		return tx.Create(owner).Error
+	})
+}
+
+func (r *organizationRepo) FindByID(ctx context.Context, id uint) (*models.Organization, error) {
+	var organization models.Organization
+	err := r.db.WithContext(ctx).First(&organization, id).Error
+	return &organization, err
+}
+
+func (r *organizationRepo) AddMember(ctx context.Context, membership *models.Membership) error {
+	return r.db.WithContext(ctx).Create(membership).Error
+}
+
+func (r *organizationRepo) FindMembership(ctx context.Context, organizationID, userID uint) (*models.Membership, error) {
+	var membership models.Membership
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&membership).Error
+	return &membership, err
+}
+
+func (r *organizationRepo) ListMembers(ctx context.Context, organizationID uint) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := r.db.WithContext(ctx).
+		// Chỉ nạp các cột công khai, thành viên cùng tổ chức không được xem dữ liệu cá nhân của nhau
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "full_name", "email") }).
+		Where("organization_id = ?", organizationID).
+		Order("created_at ASC").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+func (r *organizationRepo) ListByUser(ctx context.Context, userID uint) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := r.db.WithContext(ctx).
+		Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+func (r *organizationRepo) UpdateMemberRole(ctx context.Context, membership *models.Membership) error {
+	return r.db.WithContext(ctx).Model(membership).Update("role", membership.Role).Error
+}
+
+func (r *organizationRepo) RemoveMember(ctx context.Context, organizationID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&models.Membership{}).Error
+}
+
+func (r *organizationRepo) CountOwners(ctx context.Context, organizationID uint) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.Membership{}).
+		Where("organization_id = ? AND role = ?", organizationID, models.OrgRoleOwner).
+		Count(&total).Error
+	return total, err
+}
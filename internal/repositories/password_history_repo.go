@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository interface {
+	Add(ctx context.Context, entry *models.PasswordHistory) error
+	ListRecent(ctx context.Context, userID uint, limit int) ([]models.PasswordHistory, error)
+	Prune(ctx context.Context, userID uint, keep int) error
+}
+
+type passwordHistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepo{db: db}
+}
+
+func (r *passwordHistoryRepo) Add(ctx context.Context, entry *models.PasswordHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// ListRecent trả về tối đa "limit" hash gần nhất, mới nhất đứng đầu
+func (r *passwordHistoryRepo) ListRecent(ctx context.Context, userID uint, limit int) ([]models.PasswordHistory, error) {
+	var entries []models.PasswordHistory
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// Prune chỉ giữ lại "keep" hash gần nhất của user, xoá phần cũ hơn
+func (r *passwordHistoryRepo) Prune(ctx context.Context, userID uint, keep int) error {
+	recent := r.db.Model(&models.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(keep)
+
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND id NOT IN (?)", userID, recent).
+		Delete(&models.PasswordHistory{}).Error
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	FindByPrefix(ctx context.Context, prefix string) (*models.PersonalAccessToken, error)
+	ListActiveByUser(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID uint, id uint) (bool, error)
+	TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type personalAccessTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepo{db: db}
+}
+
+func (r *personalAccessTokenRepo) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *personalAccessTokenRepo) FindByPrefix(ctx context.Context, prefix string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&token).Error
+	return &token, err
+}
+
+func (r *personalAccessTokenRepo) ListActiveByUser(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("created_at desc").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke thu hồi token của đúng user sở hữu, trả về false nếu không tìm thấy
+func (r *personalAccessTokenRepo) Revoke(ctx context.Context, userID uint, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *personalAccessTokenRepo) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
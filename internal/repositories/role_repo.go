@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *models.Role) error
+	FindByID(ctx context.Context, id uint) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	List(ctx context.Context) ([]models.Role, error)
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id uint) error
+	CountUsers(ctx context.Context, name string) (int64, error)
+	SyncPermissions(ctx context.Context, catalog []models.Permission) error
+	FindPermissions(ctx context.Context, names []string) ([]models.Permission, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+}
+
+type roleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepo{db: db}
+}
+
+func (r *roleRepo) Create(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+func (r *roleRepo) FindByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").First(&role, id).Error
+	return &role, err
+}
+
+func (r *roleRepo) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	return &role, err
+}
+
+func (r *roleRepo) List(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// Update lưu role và thay toàn bộ danh sách quyền trong bảng role_permissions
+func (r *roleRepo) Update(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Permissions").Replace(role.Permissions)
+	})
+}
+
+func (r *roleRepo) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Select("Permissions").Delete(&models.Role{ID: id}).Error
+}
+
+// CountUsers đếm số user (chưa bị xoá) đang được gán role
+func (r *roleRepo) CountUsers(ctx context.Context, name string) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", name).Count(&total).Error
+	return total, err
+}
+
+// SyncPermissions thêm các quyền mới trong danh mục và cập nhật mô tả của quyền đã có
+func (r *roleRepo) SyncPermissions(ctx context.Context, catalog []models.Permission) error {
+	permissions := make([]models.Permission, len(catalog))
+	copy(permissions, catalog)
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description"}),
+	}).Create(&permissions).Error
+}
+
+func (r *roleRepo) FindPermissions(ctx context.Context, names []string) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepo) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&permissions).Error
+	return permissions, err
+}
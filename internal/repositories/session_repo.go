@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id uint) (*models.Session, error)
+	ListActiveByUser(ctx context.Context, userID uint) ([]models.Session, error)
+	Update(ctx context.Context, session *models.Session) error
+	RotateRefreshToken(ctx context.Context, session *models.Session, oldTokenID string) (bool, error)
+	Revoke(ctx context.Context, id uint) error
+	RevokeAllByUser(ctx context.Context, userID uint, exceptID uint) error
+	RevokeAllByClient(ctx context.Context, clientID string) error
+	SetOrganization(ctx context.Context, id uint, organizationID *uint) error
+	SetAuthenticatedAt(ctx context.Context, id uint, authenticatedAt time.Time) error
+}
+
+type sessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepo{db: db}
+}
+
+func (r *sessionRepo) Create(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *sessionRepo) FindByID(ctx context.Context, id uint) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).First(&session, id).Error
+	return &session, err
+}
+
+func (r *sessionRepo) ListActiveByUser(ctx context.Context, userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepo) Update(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+// RotateRefreshToken thay jti cũ bằng jti mới một cách nguyên tử (Compare-And-Swap).
+// Trả về false nếu jti cũ đã bị dùng trước đó (hai request cùng xoay một token)
+func (r *sessionRepo) RotateRefreshToken(ctx context.Context, session *models.Session, oldTokenID string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND refresh_token_id = ? AND revoked_at IS NULL", session.ID, oldTokenID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": session.RefreshTokenID,
+			"last_used_at":     session.LastUsedAt,
+			"expires_at":       session.ExpiresAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *sessionRepo) Revoke(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllByUser thu hồi mọi phiên của user, trừ phiên exceptID (truyền 0 để thu hồi tất cả)
+func (r *sessionRepo) RevokeAllByUser(ctx context.Context, userID uint, exceptID uint) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllByClient thu hồi mọi phiên đã cấp cho một OAuth client (khi client bị thu hồi)
+func (r *sessionRepo) RevokeAllByClient(ctx context.Context, clientID string) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("client_id = ? AND revoked_at IS NULL", clientID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// SetOrganization đổi tổ chức đang làm việc của phiên (nil = không chọn tổ chức nào)
+func (r *sessionRepo) SetOrganization(ctx context.Context, id uint, organizationID *uint) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("organization_id", organizationID).Error
+}
+
+// SetAuthenticatedAt ghi nhận user vừa xác thực lại trên phiên này
+func (r *sessionRepo) SetAuthenticatedAt(ctx context.Context, id uint, authenticatedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("authenticated_at", authenticatedAt).Error
+}
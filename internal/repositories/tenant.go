@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type tenantKey struct{}
+
+// WithTenant gắn tổ chức đang làm việc vào context. Từ đó các truy vấn user chỉ thấy thành viên của tổ chức này
+func WithTenant(ctx context.Context, organizationID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, organizationID)
+}
+
+// TenantFromContext trả về tổ chức đang làm việc (nếu có)
+func TenantFromContext(ctx context.Context) (uint, bool) {
+	organizationID, ok := ctx.Value(tenantKey{}).(uint)
+	return organizationID, ok && organizationID != 0
+}
+
+// tenantUsers giới hạn truy vấn trên bảng users trong phạm vi thành viên của tenant trong context
+func tenantUsers(ctx context.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		organizationID, ok := TenantFromContext(ctx)
+		if !ok {
+			return db
+		}
+		members := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.Membership{}).
+			Select("user_id").
+			Where("organization_id = ?", organizationID)
+		return db.Where("users.id IN (?)", members)
+	}
+}
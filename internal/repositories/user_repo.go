@@ -11,12 +11,16 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByVerifiedPhone(ctx context.Context, phone string) (*models.User, error)
 	FindByID(ctx context.Context, id uint) (*models.User, error)
-	FindByResetOTP(ctx context.Context, OTP string) (*models.User, error) // [BỔ SUNG]
 	GetList(ctx context.Context, pagination utils.Pagination) ([]models.User, int64, error)
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id uint) error
 	Purge(ctx context.Context, id uint) error
+	ConsumeResetAttempt(ctx context.Context, id uint, maxAttempts int) (bool, error)
+	ConsumePhoneOTPAttempt(ctx context.Context, id uint, maxAttempts int) (bool, error)
+	ClaimMagicLink(ctx context.Context, id uint, tokenID string) (bool, error)
+	ClaimTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
 }
 
 type userRepo struct {
@@ -31,21 +35,24 @@ func (r *userRepo) Create(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
+// FindByID, GetList, Delete và Purge chỉ thấy thành viên của tenant khi context có tổ chức đang làm việc
 func (r *userRepo) FindByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).First(&user, id).Error
+	err := r.db.WithContext(ctx).Scopes(tenantUsers(ctx)).First(&user, id).Error
 	return &user, err
 }
 
+// FindByEmail tra cứu toàn hệ thống vì email là định danh đăng nhập duy nhất (không phụ thuộc tenant)
 func (r *userRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	return &user, err
 }
 
-func (r *userRepo) FindByResetOTP(ctx context.Context, otp string) (*models.User, error) {
+// FindByVerifiedPhone cũng tra cứu toàn hệ thống, chỉ số đã xác thực mới dùng được như một định danh
+func (r *userRepo) FindByVerifiedPhone(ctx context.Context, phone string) (*models.User, error) {
 	var user models.User
-	err := r.db.WithContext(ctx).Where("reset_password_otp = ?", otp).First(&user).Error
+	err := r.db.WithContext(ctx).Where("phone = ? AND phone_verified_at IS NOT NULL", phone).First(&user).Error
 	return &user, err
 }
 
@@ -53,7 +60,7 @@ func (r *userRepo) GetList(ctx context.Context, pagination utils.Pagination) ([]
 	var users []models.User
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.User{})
+	query := r.db.WithContext(ctx).Model(&models.User{}).Scopes(tenantUsers(ctx))
 
 	if pagination.Keyword != "" {
 		query = query.Where("email ILIKE ?", "%"+pagination.Keyword+"%")
@@ -76,9 +83,43 @@ func (r *userRepo) Update(ctx context.Context, user *models.User) error {
 }
 
 func (r *userRepo) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
+	return r.db.WithContext(ctx).Scopes(tenantUsers(ctx)).Delete(&models.User{}, id).Error
 }
 
 func (r *userRepo) Purge(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Unscoped().Delete(&models.User{}, id).Error
+	return r.db.WithContext(ctx).Unscoped().Scopes(tenantUsers(ctx)).Delete(&models.User{}, id).Error
+}
+
+// ConsumeResetAttempt giữ chỗ một lượt nhập mã OTP đặt lại mật khẩu một cách nguyên tử.
+// Trả về false khi mã đã hết lượt thử, kể cả khi nhiều request đoán mã chạy song song
+func (r *userRepo) ConsumeResetAttempt(ctx context.Context, id uint, maxAttempts int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND reset_otp_hash IS NOT NULL AND reset_otp_attempts < ?", id, maxAttempts).
+		UpdateColumn("reset_otp_attempts", gorm.Expr("reset_otp_attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ConsumePhoneOTPAttempt giống ConsumeResetAttempt nhưng cho mã xác thực số điện thoại
+func (r *userRepo) ConsumePhoneOTPAttempt(ctx context.Context, id uint, maxAttempts int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND phone_otp_hash IS NOT NULL AND phone_otp_attempts < ?", id, maxAttempts).
+		UpdateColumn("phone_otp_attempts", gorm.Expr("phone_otp_attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClaimMagicLink đánh dấu link đăng nhập đã dùng. Trả về false khi link đã được dùng hoặc bị thay bằng link mới hơn
+func (r *userRepo) ClaimMagicLink(ctx context.Context, id uint, tokenID string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND magic_link_token_id = ?", id, tokenID).
+		UpdateColumn("magic_link_token_id", nil)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClaimTOTPStep ghi nhận time-step TOTP vừa dùng. Trả về false khi mã của step này (hoặc mới hơn)
+// đã được dùng, kể cả khi hai request gửi cùng một mã chạy song song
+func (r *userRepo) ClaimTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", id, step).
+		UpdateColumn("two_factor_last_step", step)
+	return result.RowsAffected == 1, result.Error
 }
@@ -8,6 +8,7 @@ import (
 	"go-core-api/internal/models"
 	"go-core-api/internal/repositories"
 	"go-core-api/pkg/config"
+	"go-core-api/pkg/jwtkeys"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -17,10 +18,33 @@ func SetupRouter(
 	authHandler *handlers.AuthHandler,
 	userHandler *handlers.UserHandler,
 	uploadHandler *handlers.UploadHandler,
+	sessionHandler *handlers.SessionHandler,
+	twoFactorHandler *handlers.TwoFactorHandler,
+	tokenHandler *handlers.PersonalAccessTokenHandler,
+	wellKnownHandler *handlers.WellKnownHandler,
+	oauthServerHandler *handlers.OAuthServerHandler,
+	roleHandler *handlers.RoleHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	invitationHandler *handlers.InvitationHandler,
+	loginHistoryHandler *handlers.LoginHistoryHandler,
+	keys *jwtkeys.KeySet,
 	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	tokenRepo repositories.PersonalAccessTokenRepository,
+	roleRepo repositories.RoleRepository,
+	orgRepo repositories.OrganizationRepository,
+	impersonationRepo repositories.ImpersonationLogRepository,
 ) *gin.Engine {
 	r := gin.New()
 	cfg := config.AppConfig
+	requireAuth := middlewares.RequireAuth(keys, userRepo, sessionRepo, tokenRepo, roleRepo, orgRepo, impersonationRepo)
+
+	// Thao tác nhạy cảm yêu cầu vừa xác thực lại qua POST /auth/reauthenticate, mặc định trong 10 phút
+	reauthMaxAge := time.Duration(cfg.Auth.ReauthMaxAgeMinutes) * time.Minute
+	if reauthMaxAge <= 0 {
+		reauthMaxAge = 10 * time.Minute
+	}
+	recentAuth := middlewares.RequireRecentAuth(reauthMaxAge)
 
 	r.Use(middlewares.ZapLogger(), gin.Recovery())
 
@@ -28,13 +52,32 @@ func SetupRouter(
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{cfg.Server.Domain}, // Thay "*" bằng domain Frontend thực tế
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-CSRF-Token"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	r.Static("/uploads", "./uploads")
 
+	// Public key để các service khác tự verify Access Token
+	r.GET("/.well-known/jwks.json", wellKnownHandler.JWKS)
+
+	// Hệ thống đóng vai trò OpenID Provider cho các ứng dụng nội bộ (authorization code + PKCE)
+	r.GET("/.well-known/openid-configuration", oauthServerHandler.Discovery)
+	oauthRouters := r.Group("/oauth")
+	oauthRouters.Use(middlewares.RateLimitMiddleware())
+	{
+		oauthRouters.GET("/authorize", oauthServerHandler.AuthorizePage)
+		oauthRouters.POST("/authorize", oauthServerHandler.AuthorizeSubmit)
+		oauthRouters.POST("/token", oauthServerHandler.Token)
+		oauthRouters.POST("/revoke", oauthServerHandler.Revoke)
+	}
+	// Gateway gọi introspect cho mỗi request từ cùng một IP nên không qua rate limit theo IP (đã bắt buộc client secret)
+	r.POST("/oauth/introspect", oauthServerHandler.Introspect)
+	userInfoScope := middlewares.RequireScope(models.ScopeOpenID)
+	r.GET("/userinfo", requireAuth, userInfoScope, oauthServerHandler.UserInfo)
+	r.POST("/userinfo", requireAuth, userInfoScope, oauthServerHandler.UserInfo)
+
 	// Áp dụng giới hạn ram mặc định cho file tải lên ở level Router (8MB bộ nhớ RAM, phần thừa ghi ra temp disk)
 	r.MaxMultipartMemory = 8 << 20
 
@@ -45,42 +88,133 @@ func SetupRouter(
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/2fa", authHandler.Login2FA)
+			auth.POST("/magic-link", authHandler.RequestMagicLink)
+			auth.GET("/magic-link/consume", authHandler.MagicLinkLanding)
+			auth.POST("/magic-link/consume", authHandler.ConsumeMagicLink)
 			auth.POST("/refresh-token", authHandler.RefreshToken)
-			auth.POST("/logout", middlewares.RequireAuth(cfg.JWT.Secret, userRepo), authHandler.Logout)
+			auth.POST("/logout", requireAuth, middlewares.DenyAPIToken(), middlewares.DenyImpersonation(), authHandler.Logout)
+			auth.POST("/reauthenticate", requireAuth, middlewares.DenyAPIToken(), middlewares.DenyImpersonation(), authHandler.Reauthenticate)
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.GET("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			auth.POST("/email-change/confirm", authHandler.ConfirmEmailChange)
+			auth.POST("/email-change/revert", authHandler.RevertEmailChange)
+			auth.POST("/invitations/accept", invitationHandler.AcceptInvitation)
+
+			// Đăng nhập bằng tài khoản bên ngoài (authorization code + PKCE)
+			auth.GET("/oauth/:provider", authHandler.OAuthRedirect)
+			auth.GET("/oauth/:provider/callback", authHandler.OAuthCallback)
 		}
 
+		// Trang quản trị chỉ dành cho đăng nhập trực tiếp: API token và token cấp cho OAuth client bị chặn
 		protected := v1.Group("/admin")
-		protected.Use(middlewares.RequireAuth(cfg.JWT.Secret, userRepo), middlewares.RequireRole(models.RoleAdmin))
+		protected.Use(requireAuth, middlewares.DenyAPIToken())
 		{
-			protected.GET("/dashboard", func(c *gin.Context) {
+			protected.GET("/dashboard", middlewares.RequirePermission(models.PermDashboardView), func(c *gin.Context) {
 				userID, _ := c.Get("user_id")
 				c.JSON(200, gin.H{"message": "Chào mừng Admin!", "your_id": userID})
 			})
+
+			// Đăng ký ứng dụng được phép đăng nhập qua hệ thống
+			oauthClientRouters := protected.Group("/oauth/clients")
+			oauthClientRouters.Use(middlewares.RequirePermission(models.PermOAuthClientsManage))
+			{
+				oauthClientRouters.GET("", oauthServerHandler.ListClients)
+				oauthClientRouters.POST("", oauthServerHandler.RegisterClient)
+				oauthClientRouters.DELETE("/:id", oauthServerHandler.RevokeClient)
+			}
+
+			// Quản lý role và quyền
+			roleRouters := protected.Group("")
+			roleRouters.Use(middlewares.RequirePermission(models.PermRolesManage))
+			{
+				roleRouters.GET("/permissions", roleHandler.ListPermissions)
+				roleRouters.GET("/roles", roleHandler.ListRoles)
+				roleRouters.POST("/roles", roleHandler.CreateRole)
+				roleRouters.PUT("/roles/:id", recentAuth, roleHandler.UpdateRole)
+				roleRouters.DELETE("/roles/:id", recentAuth, roleHandler.DeleteRole)
+			}
+		}
+
+		// Tổ chức (tenant): thao tác trên tổ chức đang làm việc (claim org_id của Access Token)
+		orgRouters := v1.Group("/orgs")
+		orgRouters.Use(requireAuth, middlewares.DenyAPIToken())
+		{
+			orgRouters.GET("", organizationHandler.ListMyOrganizations)
+			orgRouters.POST("", organizationHandler.CreateOrganization)
+			orgRouters.POST("/switch", middlewares.DenyImpersonation(), organizationHandler.SwitchOrganization)
+			orgRouters.POST("/invitations/accept", middlewares.DenyImpersonation(), organizationHandler.AcceptInvitation)
+			orgRouters.POST("/current/invitations", middlewares.RequireOrgRole(models.OrgRoleAdmin), organizationHandler.InviteMember)
+
+			memberRouters := orgRouters.Group("/current/members")
+			{
+				memberRouters.GET("", middlewares.RequireOrgRole(models.OrgRoleMember), organizationHandler.ListMembers)
+				memberRouters.PUT("/:user_id", middlewares.RequireOrgRole(models.OrgRoleAdmin), organizationHandler.UpdateMember)
+				memberRouters.DELETE("/:user_id", middlewares.RequireOrgRole(models.OrgRoleAdmin), organizationHandler.RemoveMember)
+			}
 		}
 
 		upload := v1.Group("/upload")
-		upload.Use(middlewares.RequireAuth(cfg.JWT.Secret, userRepo))
+		upload.Use(requireAuth, middlewares.RequireScope(models.ScopeUpload), middlewares.RequireVerifiedEmail())
 		{
 			upload.POST("/image", uploadHandler.UploadImage)
 		}
 
 		userRouters := v1.Group("/users")
-		userRouters.Use(middlewares.RequireAuth(cfg.JWT.Secret, userRepo))
+		userRouters.Use(requireAuth)
 		{
-			userRouters.PUT("/me/password", userHandler.ChangePassword)
-			userRouters.GET("/me", userHandler.GetMe)
-			userRouters.PUT("/me", userHandler.UpdateProfile)
+			userRouters.GET("/me", middlewares.RequireScope(models.ScopeProfileRead), userHandler.GetMe)
+			userRouters.PUT("/me", middlewares.RequireScope(models.ScopeProfileWrite), userHandler.UpdateProfile)
+
+			// Quản lý thông tin đăng nhập: bắt buộc đăng nhập trực tiếp, API token và token giả lập không được phép
+			credentialRouters := userRouters.Group("/me")
+			credentialRouters.Use(middlewares.DenyAPIToken(), middlewares.DenyImpersonation())
+			{
+				credentialRouters.PUT("/password", userHandler.ChangePassword)
+				credentialRouters.POST("/email", authHandler.RequestEmailChange)
+				credentialRouters.POST("/phone/send-otp", authHandler.SendPhoneOTP)
+				credentialRouters.POST("/phone/verify", authHandler.VerifyPhone)
+				credentialRouters.GET("/sessions", sessionHandler.ListSessions)
+				credentialRouters.GET("/login-history", loginHistoryHandler.ListMyHistory)
+				credentialRouters.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+				credentialRouters.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+				credentialRouters.POST("/2fa", twoFactorHandler.Enroll)
+				credentialRouters.POST("/2fa/confirm", twoFactorHandler.Confirm)
+				credentialRouters.DELETE("/2fa", recentAuth, twoFactorHandler.Disable)
+				credentialRouters.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+				credentialRouters.GET("/tokens", tokenHandler.ListTokens)
+				credentialRouters.POST("/tokens", tokenHandler.CreateToken)
+				credentialRouters.DELETE("/tokens/:id", tokenHandler.RevokeToken)
+			}
 
+			// Quản trị user: mỗi thao tác yêu cầu một quyền riêng (VD: support xem được user nhưng không purge được)
 			adminUserRouters := userRouters.Group("")
-			adminUserRouters.Use(middlewares.RequireRole(models.RoleAdmin))
 			{
-				adminUserRouters.GET("", userHandler.GetList)
-				adminUserRouters.GET("/:id", userHandler.GetUser)
-				adminUserRouters.PUT("/:id", userHandler.AdminUpdateUser)
-				adminUserRouters.DELETE("/:id", userHandler.DeleteUser)
-				adminUserRouters.DELETE("/:id/purge", userHandler.PurgeUser)
+				readScope := middlewares.RequireScope(models.ScopeUsersRead)
+				writeScope := middlewares.RequireScope(models.ScopeUsersWrite)
+				can := middlewares.RequirePermission
+				// Thao tác ghi được ghi nhận theo người thao tác: admin đang giả lập user khác không được dùng
+				noImpersonation := middlewares.DenyImpersonation()
+
+				adminUserRouters.GET("", readScope, can(models.PermUsersRead), userHandler.GetList)
+				adminUserRouters.GET("/:id", readScope, can(models.PermUsersRead), userHandler.GetUser)
+				adminUserRouters.PUT("/:id", writeScope, noImpersonation, can(models.PermUsersWrite), recentAuth, userHandler.AdminUpdateUser)
+				adminUserRouters.DELETE("/:id", writeScope, noImpersonation, can(models.PermUsersDelete), userHandler.DeleteUser)
+				adminUserRouters.DELETE("/:id/purge", writeScope, noImpersonation, can(models.PermUsersPurge), recentAuth, userHandler.PurgeUser)
+				adminUserRouters.POST("/:id/unlock", writeScope, noImpersonation, can(models.PermUsersUnlock), userHandler.UnlockUser)
+				adminUserRouters.POST("/:id/suspend", writeScope, noImpersonation, can(models.PermUsersSuspend), userHandler.SuspendUser)
+				adminUserRouters.POST("/:id/unsuspend", writeScope, noImpersonation, can(models.PermUsersSuspend), userHandler.UnsuspendUser)
+				adminUserRouters.GET("/:id/login-history", readScope, can(models.PermUsersRead), loginHistoryHandler.ListUserHistory)
+				adminUserRouters.POST("/:id/impersonate", middlewares.DenyAPIToken(), middlewares.DenyImpersonation(), can(models.PermUsersImpersonate), recentAuth, authHandler.Impersonate)
+
+				// Mời user mới qua email (trong tổ chức đang làm việc nếu có)
+				adminUserRouters.GET("/invitations", readScope, can(models.PermUsersInvite), invitationHandler.ListInvitations)
+				adminUserRouters.POST("/invitations", writeScope, noImpersonation, can(models.PermUsersInvite), invitationHandler.CreateInvitation)
+				adminUserRouters.POST("/invitations/:id/resend", writeScope, noImpersonation, can(models.PermUsersInvite), invitationHandler.ResendInvitation)
+				adminUserRouters.DELETE("/invitations/:id", writeScope, noImpersonation, can(models.PermUsersInvite), invitationHandler.RevokeInvitation)
 			}
 		}
 	}
@@ -1,15 +1,22 @@
 package server
 
 import (
+	"context"
 	"os"
 
 	"go-core-api/internal/handlers"
 	"go-core-api/internal/repositories"
 	"go-core-api/internal/routers"
 	"go-core-api/internal/services"
+	"go-core-api/pkg/breached"
+	"go-core-api/pkg/cache"
 	"go-core-api/pkg/config"
+	"go-core-api/pkg/hasher"
+	"go-core-api/pkg/jwtkeys"
 	"go-core-api/pkg/logger"
 	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/oauth"
+	"go-core-api/pkg/sms"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -17,7 +24,7 @@ import (
 )
 
 // SetupDependenciesAndRouter gom toàn bộ logic tiêm phụ thuộc (DI) vào một chỗ
-func SetupDependenciesAndRouter(db *gorm.DB, cfg *config.Config, mailService mailer.Mailer) *gin.Engine {
+func SetupDependenciesAndRouter(db *gorm.DB, cfg *config.Config, mailService mailer.Mailer, smsSender sms.Sender) *gin.Engine {
 	// 1. Cấu hình môi trường cho Gin
 	gin.SetMode(gin.ReleaseMode)
 
@@ -26,18 +33,90 @@ func SetupDependenciesAndRouter(db *gorm.DB, cfg *config.Config, mailService mai
 		logger.Fatal("Không thể tạo thư mục uploads", zap.Error(err))
 	}
 
+	// Nạp bộ khoá ký JWT (HS256 hoặc khoá bất đối xứng có xoay vòng)
+	keys, err := jwtkeys.Load(cfg.JWT.Secret, cfg.JWT.SigningKeyID, cfg.JWT.Keys)
+	if err != nil {
+		logger.Fatal("Không thể nạp khoá ký JWT", zap.Error(err))
+	}
+
+	passwordHasher, err := hasher.New(cfg.Password)
+	if err != nil {
+		logger.Fatal("Cấu hình băm mật khẩu không hợp lệ", zap.Error(err))
+	}
+
+	// Danh sách mật khẩu đã bị lộ: bộ đi kèm mã nguồn + file bổ sung trong cấu hình (nếu có)
+	breachedChecker, err := breached.New(cfg.PasswordPolicy.BreachedListFile)
+	if err != nil {
+		logger.Fatal("Không thể nạp danh sách mật khẩu đã lộ", zap.Error(err))
+	}
+
+	// Các provider đăng nhập bằng tài khoản bên ngoài (Google, GitHub, OIDC...)
+	oauthProviders, err := oauth.NewRegistry(cfg.OAuth)
+	if err != nil {
+		logger.Fatal("Cấu hình OAuth provider không hợp lệ", zap.Error(err))
+	}
+
+	// Cache tra cứu user, role và membership (RequireAuth đọc ở mỗi request), bỏ trống driver = không dùng cache
+	authCache, err := cache.New(cfg.Cache)
+	if err != nil {
+		logger.Fatal("Cấu hình cache không hợp lệ", zap.Error(err))
+	}
+
 	// 2. Khởi tạo tầng Repositories (Data Access)
 	userRepo := repositories.NewUserRepository(db)
+	if authCache != nil {
+		userRepo = repositories.NewCachedUserRepository(userRepo, authCache, cfg.Cache.TTL())
+	}
+	sessionRepo := repositories.NewSessionRepository(db)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	tokenRepo := repositories.NewPersonalAccessTokenRepository(db)
+	throttleRepo := repositories.NewLoginThrottleRepository(db)
+	loginHistoryRepo := repositories.NewLoginHistoryRepository(db)
+	passwordHistoryRepo := repositories.NewPasswordHistoryRepository(db)
+	identityRepo := repositories.NewIdentityRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	if authCache != nil {
+		roleRepo = repositories.NewCachedRoleRepository(roleRepo, authCache, cfg.Cache.TTL())
+		orgRepo = repositories.NewCachedOrganizationRepository(orgRepo, authCache, cfg.Cache.TTL())
+	}
+	invitationRepo := repositories.NewInvitationRepository(db)
+	impersonationRepo := repositories.NewImpersonationLogRepository(db)
+	oauthClientRepo := repositories.NewOAuthClientRepository(db)
+	oauthCodeRepo := repositories.NewOAuthCodeRepository(db)
 
 	// 3. Khởi tạo tầng Services (Business Logic)
-	authService := services.NewAuthService(userRepo, cfg.JWT.Secret, mailService)
-	userService := services.NewUserService(userRepo)
+	twoFactorService := services.NewTwoFactorService(userRepo, recoveryCodeRepo)
+	passwordPolicy := services.NewPasswordPolicy(passwordHistoryRepo, passwordHasher, breachedChecker)
+	authService := services.NewAuthService(userRepo, sessionRepo, throttleRepo, loginHistoryRepo, identityRepo, roleRepo, orgRepo, twoFactorService, passwordHasher, passwordPolicy, keys, oauthProviders, mailService, smsSender)
+	userService := services.NewUserService(userRepo, throttleRepo, roleRepo, passwordHasher, passwordPolicy)
+	sessionService := services.NewSessionService(sessionRepo)
+	tokenService := services.NewPersonalAccessTokenService(tokenRepo)
+	roleService := services.NewRoleService(roleRepo, userRepo)
+	organizationService := services.NewOrganizationService(orgRepo, userRepo, sessionRepo, invitationRepo, authService, keys, mailService)
+	loginHistoryService := services.NewLoginHistoryService(loginHistoryRepo, userRepo)
+	invitationService := services.NewInvitationService(invitationRepo, userRepo, roleRepo, orgRepo, passwordHasher, passwordPolicy, keys, mailService)
+
+	// Đồng bộ danh mục quyền và các role dựng sẵn (admin, user) trước khi nhận request
+	if err := roleService.SyncDefaults(context.Background()); err != nil {
+		logger.Fatal("Không thể khởi tạo role và quyền mặc định", zap.Error(err))
+	}
+	oauthServerService := services.NewOAuthServerService(userRepo, sessionRepo, oauthClientRepo, oauthCodeRepo, roleRepo, orgRepo, keys)
 
 	// 4. Khởi tạo tầng Handlers (HTTP Layer)
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
 	uploadHandler := handlers.NewUploadHandler()
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	tokenHandler := handlers.NewPersonalAccessTokenHandler(tokenService)
+	wellKnownHandler := handlers.NewWellKnownHandler(keys)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	loginHistoryHandler := handlers.NewLoginHistoryHandler(loginHistoryService)
+	oauthServerHandler := handlers.NewOAuthServerHandler(oauthServerService, authService)
 
 	// 5. Ráp tất cả vào Router và trả về
-	return routers.SetupRouter(authHandler, userHandler, uploadHandler, userRepo)
+	return routers.SetupRouter(authHandler, userHandler, uploadHandler, sessionHandler, twoFactorHandler, tokenHandler, wellKnownHandler, oauthServerHandler, roleHandler, organizationHandler, invitationHandler, loginHistoryHandler, keys, userRepo, sessionRepo, tokenRepo, roleRepo, orgRepo, impersonationRepo)
 }
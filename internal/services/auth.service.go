@@ -9,14 +9,18 @@ import (
 	"go-core-api/internal/repositories"
 	"go-core-api/pkg/config"
 	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/hasher"
+	"go-core-api/pkg/jwtkeys"
 	"go-core-api/pkg/logger"
 	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/oauth"
+	"go-core-api/pkg/sms"
 	"go-core-api/pkg/utils"
 	"go-core-api/templates"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // TokenDetails chứa thông tin về AccessToken và RefreshToken sau khi login
@@ -25,118 +29,402 @@ type TokenDetails struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// MFAChallenge được trả về thay cho TokenDetails khi tài khoản đã bật 2FA.
+// Client dùng ChallengeToken kèm mã 2FA gọi /auth/login/2fa để nhận cặp token thật
+type MFAChallenge struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"` // giây
+}
+
+const mfaChallengeTTL = 5 * time.Minute
+
+// Mã OTP đặt lại mật khẩu
+const (
+	resetOTPTTL            = 15 * time.Minute
+	resetOTPMaxAttempts    = 5           // Số lần nhập sai tối đa trước khi mã bị huỷ
+	resetOTPResendInterval = time.Minute // Khoảng cách tối thiểu giữa 2 lần gửi mã
+)
+
+// ClientInfo mô tả thiết bị đang đăng nhập, dùng để khởi tạo phiên (session)
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+	ClientID   string // OAuth client được cấp phiên (rỗng khi đăng nhập trực tiếp vào hệ thống)
+}
+
 type AuthService interface {
 	Register(ctx context.Context, email, password string) error
-	Login(ctx context.Context, email, password string) (*TokenDetails, error)
-	GenerateTokens(userID uint, role string, tokenVersion int) (*TokenDetails, error)
+	Login(ctx context.Context, email, password string, client ClientInfo) (*TokenDetails, *MFAChallenge, error)
+	Authenticate(ctx context.Context, email, password, code string, client ClientInfo) (*models.User, error)
+	LoginWith2FA(ctx context.Context, challengeToken, code string, client ClientInfo) (*TokenDetails, error)
+	GenerateTokens(ctx context.Context, user *models.User, session *models.Session) (*TokenDetails, error)
 	RefreshToken(ctx context.Context, tokenString string) (*TokenDetails, error)
-	RevokeToken(ctx context.Context, userID uint) error
+	RevokeToken(ctx context.Context, userID uint, sessionID uint) error
 	ForgotPassword(ctx context.Context, email string) error
-	ResetPassword(ctx context.Context, token string, newPassword string) error
+	ResetPassword(ctx context.Context, email, otp, newPassword string) error
+	ForgotPasswordByPhone(ctx context.Context, phone string) error
+	ResetPasswordByPhone(ctx context.Context, phone, otp, newPassword string) error
+	SendPhoneOTP(ctx context.Context, userID uint) error
+	VerifyPhone(ctx context.Context, userID uint, otp string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestMagicLink(ctx context.Context, email string) error
+	ConsumeMagicLink(ctx context.Context, token string, client ClientInfo) (*TokenDetails, *MFAChallenge, error)
+	Reauthenticate(ctx context.Context, userID, sessionID uint, password, code string, client ClientInfo) (*TokenDetails, error)
+	ResendVerification(ctx context.Context, email string) error
+	BeginOAuth(ctx context.Context, providerName string) (*OAuthStart, error)
+	CompleteOAuth(ctx context.Context, providerName, code, state, stateToken string, client ClientInfo) (*TokenDetails, *MFAChallenge, error)
+	Impersonate(ctx context.Context, actorID, sessionID, userID uint) (*ImpersonationToken, error)
+	RequestEmailChange(ctx context.Context, userID uint, password, newEmail string, client ClientInfo) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	RevertEmailChange(ctx context.Context, token string) error
 }
 
 type authService struct {
-	repo   repositories.UserRepository
-	secret string
-	mailer mailer.Mailer
+	repo         repositories.UserRepository
+	sessionRepo  repositories.SessionRepository
+	identityRepo repositories.IdentityRepository
+	roleRepo     repositories.RoleRepository
+	orgRepo      repositories.OrganizationRepository
+	twoFactor    TwoFactorService
+	guard        *loginGuard
+	history      *loginHistory
+	hasher       hasher.PasswordHasher
+	policy       PasswordPolicy
+	keys         *jwtkeys.KeySet
+	oauth        *oauth.Registry
+	mailer       mailer.Mailer
+	sms          sms.Sender
 }
 
-func NewAuthService(repo repositories.UserRepository, secret string, mail mailer.Mailer) AuthService {
+func NewAuthService(
+	repo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	throttleRepo repositories.LoginThrottleRepository,
+	loginHistoryRepo repositories.LoginHistoryRepository,
+	identityRepo repositories.IdentityRepository,
+	roleRepo repositories.RoleRepository,
+	orgRepo repositories.OrganizationRepository,
+	twoFactor TwoFactorService,
+	passwordHasher hasher.PasswordHasher,
+	policy PasswordPolicy,
+	keys *jwtkeys.KeySet,
+	oauthProviders *oauth.Registry,
+	mail mailer.Mailer,
+	smsSender sms.Sender,
+) AuthService {
 	return &authService{
-		repo:   repo,
-		secret: secret,
-		mailer: mail,
+		repo:         repo,
+		sessionRepo:  sessionRepo,
+		identityRepo: identityRepo,
+		roleRepo:     roleRepo,
+		orgRepo:      orgRepo,
+		twoFactor:    twoFactor,
+		guard:        &loginGuard{repo: throttleRepo, mailer: mail},
+		history:      &loginHistory{repo: loginHistoryRepo, mailer: mail},
+		hasher:       passwordHasher,
+		policy:       policy,
+		keys:         keys,
+		oauth:        oauthProviders,
+		mailer:       mail,
+		sms:          smsSender,
 	}
 }
 
-// THUẬT TOÁN ĐĂNG KÝ: Hash password bằng bcrypt với độ khó (cost) = 10
+// THUẬT TOÁN ĐĂNG KÝ: Hash password theo thuật toán cấu hình (mặc định argon2id)
 func (s *authService) Register(ctx context.Context, email, password string) error {
 	if _, err := s.repo.FindByEmail(ctx, email); err == nil {
 		return custom_error.ErrEmailExists
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := s.policy.Validate(ctx, "password", password, &models.User{Email: email}); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
 		return custom_error.ErrInternalServer
 	}
 
 	user := &models.User{
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Role:     models.RoleUser,
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
 		return custom_error.ErrInternalServer
 	}
+	s.policy.Remember(ctx, user.ID, hashedPassword)
 
-	utils.RunInBackground(func() {
-		subject := "🎉 Welcome to [YourApp]!"
-		body, err := templates.Render("welcome.html", map[string]interface{}{
-			"Email": email,
-			"Link":  config.AppConfig.Server.Domain,
-		})
+	// Email chào mừng chỉ được gửi sau khi user bấm link xác thực
+	return s.sendVerificationEmail(ctx, user)
+}
 
-		if err != nil {
-			logger.Error("Lỗi render template welcome", zap.Error(err))
-			return
-		}
-		if err := s.mailer.SendMail(email, subject, body); err != nil {
-			logger.Error("Lỗi gửi email chào mừng", zap.Error(err))
-		}
-	})
+// THUẬT TOÁN LOGIN & JWT
+func (s *authService) Login(ctx context.Context, email, password string, client ClientInfo) (*TokenDetails, *MFAChallenge, error) {
+	// 1 -> 4. Kiểm tra mật khẩu, khoá tài khoản và xác thực email
+	user, err := s.verifyPassword(ctx, email, password, client)
+	if err != nil {
+		s.history.record(user, email, client, models.LoginMethodPassword, err)
+		return nil, nil, err
+	}
 
-	return nil
+	// 5. Tài khoản đã bật 2FA: chưa cấp token, chỉ trả về challenge cho bước 2.
+	// Bộ đếm chưa được xoá vì bước 2FA vẫn có thể bị dò mã
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := s.issueMFAChallenge(user)
+		return nil, challenge, err
+	}
+
+	// 6. Mở phiên mới cho thiết bị này và cấp phát Token
+	s.guard.reset(ctx, user.ID, client.IP)
+	tokens, err := s.startSession(ctx, user, client)
+	s.history.record(user, email, client, models.LoginMethodPassword, err)
+	return tokens, nil, err
 }
 
-// THUẬT TOÁN LOGIN & JWT
-func (s *authService) Login(ctx context.Context, email, password string) (*TokenDetails, error) {
+// verifyPassword là phần dùng chung của mọi luồng đăng nhập bằng mật khẩu.
+// Khi thất bại vẫn trả về user (nếu email tồn tại) để ghi lịch sử đăng nhập
+func (s *authService) verifyPassword(ctx context.Context, email, password string, client ClientInfo) (*models.User, error) {
 	// 1. Tìm user
 	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
 		return nil, custom_error.ErrInvalidCredentials
 	}
 
-	// 2. So sánh mật khẩu người dùng nhập với mật khẩu hash trong DB
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	// 2. Tài khoản đang bị khoá hoặc IP này vừa thử sai liên tục -> từ chối trước khi so mật khẩu
+	if err := s.guard.check(ctx, user.ID, client.IP); err != nil {
+		return user, err
+	}
+
+	// 3. So sánh mật khẩu người dùng nhập với mật khẩu hash trong DB
+	match, needsRehash, err := s.hasher.Verify(password, user.Password)
+	if err != nil || !match {
+		s.guard.recordFailure(ctx, user, client.IP)
+		return user, custom_error.ErrInvalidCredentials
+	}
+
+	// Chỉ báo đình chỉ khi mật khẩu đúng, người ngoài không dò được tài khoản nào đang bị đình chỉ
+	if user.IsSuspended() {
+		return user, custom_error.ErrAccountSuspended
+	}
+
+	// Hash cũ (bcrypt hoặc tham số yếu hơn cấu hình hiện tại): nâng cấp ngay khi còn giữ mật khẩu gốc
+	if needsRehash {
+		s.upgradePasswordHash(ctx, user, password)
+	}
+
+	// 4. Chặn tài khoản chưa xác thực email nếu cấu hình yêu cầu
+	if config.AppConfig.Auth.EmailVerificationMode == config.EmailVerificationLogin && user.EmailVerifiedAt == nil {
+		return user, custom_error.ErrEmailNotVerified
+	}
+	return user, nil
+}
+
+// Authenticate xác thực mật khẩu và mã 2FA (nếu tài khoản đã bật) trong một bước, không mở phiên.
+// Dùng cho các form đăng nhập một trang như màn hình đồng ý cấp quyền của OAuth
+func (s *authService) Authenticate(ctx context.Context, email, password, code string, client ClientInfo) (*models.User, error) {
+	user, err := s.verifyPassword(ctx, email, password, client)
 	if err != nil {
-		return nil, custom_error.ErrInvalidCredentials
+		s.history.record(user, email, client, models.LoginMethodPassword, err)
+		return nil, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		if code == "" {
+			return nil, custom_error.ErrTwoFactorCodeRequired
+		}
+		if err := s.twoFactor.VerifyCode(ctx, user, code); err != nil {
+			if err == custom_error.ErrInvalidTwoFactorCode {
+				s.guard.recordFailure(ctx, user, client.IP)
+			}
+			s.history.record(user, email, client, models.LoginMethodTwoFactor, err)
+			return nil, err
+		}
+	}
+
+	s.guard.reset(ctx, user.ID, client.IP)
+	s.history.record(user, email, client, models.LoginMethodPassword, nil)
+	return user, nil
+}
+
+// LoginWith2FA đổi challenge token + mã TOTP/recovery code lấy cặp token thật
+func (s *authService) LoginWith2FA(ctx context.Context, challengeToken, code string, client ClientInfo) (*TokenDetails, error) {
+	claims, err := s.parseClaims(challengeToken, "mfa_challenge")
+	if err != nil {
+		return nil, custom_error.ErrInvalidMFAChallenge
+	}
+
+	userIDFloat, okID := claims["user_id"].(float64)
+	tokenVersionFloat, okVer := claims["token_version"].(float64)
+	if !okID || !okVer {
+		return nil, custom_error.ErrInvalidMFAChallenge
+	}
+
+	user, err := s.repo.FindByID(ctx, uint(userIDFloat))
+	// Đổi mật khẩu giữa chừng (TokenVersion tăng) sẽ vô hiệu hoá challenge đang chờ
+	if err != nil || user.TokenVersion != int(tokenVersionFloat) {
+		return nil, custom_error.ErrInvalidMFAChallenge
+	}
+
+	if err := s.guard.check(ctx, user.ID, client.IP); err != nil {
+		s.history.record(user, "", client, models.LoginMethodTwoFactor, err)
+		return nil, err
+	}
+
+	if err := s.twoFactor.VerifyCode(ctx, user, code); err != nil {
+		if err == custom_error.ErrInvalidTwoFactorCode {
+			s.guard.recordFailure(ctx, user, client.IP)
+		}
+		s.history.record(user, "", client, models.LoginMethodTwoFactor, err)
+		return nil, err
+	}
+
+	s.guard.reset(ctx, user.ID, client.IP)
+	tokens, err := s.startSession(ctx, user, client)
+	s.history.record(user, "", client, models.LoginMethodTwoFactor, err)
+	return tokens, err
+}
+
+// upgradePasswordHash băm lại mật khẩu theo cấu hình mới. Lỗi chỉ được ghi log, không làm hỏng lượt đăng nhập
+func (s *authService) upgradePasswordHash(ctx context.Context, user *models.User, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		logger.Error("Lỗi băm lại mật khẩu", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	user.Password = hashedPassword
+	if err := s.repo.Update(ctx, user); err != nil {
+		logger.Error("Lỗi lưu hash mật khẩu mới", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// issueMFAChallenge ký một token ngắn hạn chỉ dùng được cho bước xác thực 2FA
+func (s *authService) issueMFAChallenge(user *models.User) (*MFAChallenge, error) {
+	token, err := s.signClaims(jwt.MapClaims{
+		"token_type":    "mfa_challenge",
+		"user_id":       user.ID,
+		"token_version": user.TokenVersion,
+		"exp":           time.Now().Add(mfaChallengeTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAChallenge{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresIn:      int(mfaChallengeTTL.Seconds()),
+	}, nil
+}
+
+// startSession mở phiên cho thiết bị và cấp phát Token gắn với phiên vừa tạo
+func (s *authService) startSession(ctx context.Context, user *models.User, client ClientInfo) (*TokenDetails, error) {
+	// Chốt chặn chung cho mọi luồng đăng nhập (mật khẩu, 2FA, OAuth, magic link)
+	if user.IsSuspended() {
+		return nil, custom_error.ErrAccountSuspended
+	}
+
+	session, err := s.createSession(ctx, user.ID, client)
+	if err != nil {
+		return nil, err
 	}
+	return s.GenerateTokens(ctx, user, session)
+}
 
-	// 3. Cấp phát Token
-	return s.GenerateTokens(user.ID, user.Role, user.TokenVersion)
+// createSession lưu phiên đăng nhập của một thiết bị, thời hạn bằng thời hạn Refresh Token
+func (s *authService) createSession(ctx context.Context, userID uint, client ClientInfo) (*models.Session, error) {
+	session := newSession(userID, client, refreshTTL())
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return session, nil
 }
 
-// Logic sinh cặp Token (Access & RefreshToken)
-func (s *authService) GenerateTokens(userID uint, role string, tokenVersion int) (*TokenDetails, error) {
-	cfg := config.AppConfig.JWT
+// newSession dựng phiên mới (chưa lưu DB) với họ Refresh Token riêng
+func newSession(userID uint, client ClientInfo, ttl time.Duration) *models.Session {
+	now := time.Now()
+	deviceName := client.DeviceName
+	if deviceName == "" {
+		deviceName = client.UserAgent
+	}
+	if len(deviceName) > 100 {
+		deviceName = deviceName[:100]
+	}
 
+	return &models.Session{
+		UserID:          userID,
+		FamilyID:        uuid.NewString(),
+		RefreshTokenID:  uuid.NewString(),
+		ClientID:        client.ClientID,
+		DeviceName:      deviceName,
+		UserAgent:       client.UserAgent,
+		IP:              client.IP,
+		LastUsedAt:      now,
+		AuthenticatedAt: now,
+		ExpiresAt:       now.Add(ttl),
+	}
+}
+
+func accessTTL() time.Duration {
+	return time.Minute * time.Duration(config.AppConfig.JWT.AccessExpiration)
+}
+
+// reauthMaxAge khớp với RequireRecentAuth ở router: mặc định 10 phút
+func reauthMaxAge() time.Duration {
+	if minutes := config.AppConfig.Auth.ReauthMaxAgeMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 10 * time.Minute
+}
+
+func refreshTTL() time.Duration {
+	return time.Hour * 24 * time.Duration(config.AppConfig.JWT.RefreshExpiration)
+}
+
+// Logic sinh cặp Token (Access & RefreshToken).
+// Refresh Token mang jti hiện hành của phiên, vì vậy phải gọi sau khi phiên đã được lưu/xoay vòng
+func (s *authService) GenerateTokens(ctx context.Context, user *models.User, session *models.Session) (*TokenDetails, error) {
 	// Access Token dùng cấu hình AccessExpiration
 	accessTokenClaims := jwt.MapClaims{
 		"token_type":    "access",
-		"user_id":       userID,
-		"role":          role,
-		"token_version": tokenVersion,
-		"exp":           time.Now().Add(time.Minute * time.Duration(cfg.AccessExpiration)).Unix(),
+		"user_id":       user.ID,
+		"role":          user.Role,
+		"token_version": user.TokenVersion,
+		"session_id":    session.ID,
+		"exp":           time.Now().Add(accessTTL()).Unix(),
+	}
+	// Phiên tạo trước khi có cột authenticated_at không mang auth_time: thao tác nhạy cảm sẽ yêu cầu xác thực lại
+	if !session.AuthenticatedAt.IsZero() {
+		accessTokenClaims["auth_time"] = session.AuthenticatedAt.Unix()
+	}
+	if err := addPermissionClaims(ctx, s.roleRepo, user.Role, accessTokenClaims); err != nil {
+		return nil, err
 	}
+	s.addOrganizationClaims(ctx, user.ID, session, accessTokenClaims)
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
-	aToken, err := accessToken.SignedString([]byte(s.secret))
+	aToken, err := s.signClaims(accessTokenClaims)
 	if err != nil {
-		return nil, custom_error.ErrInternalServer
+		return nil, err
 	}
 
 	// Refresh Token dùng cấu hình RefreshExpiration
 	refreshTokenClaim := jwt.MapClaims{
-		"token_type": "refresh",
-		"user_id":    userID,
-		"exp":        time.Now().Add(time.Hour * 24 * time.Duration(cfg.RefreshExpiration)).Unix(),
+		"token_type":    "refresh",
+		"jti":           session.RefreshTokenID,
+		"family_id":     session.FamilyID,
+		"user_id":       user.ID,
+		"token_version": user.TokenVersion,
+		"session_id":    session.ID,
+		"exp":           session.ExpiresAt.Unix(),
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaim)
-	rToken, err := refreshToken.SignedString([]byte(s.secret))
+	rToken, err := s.signClaims(refreshTokenClaim)
 	if err != nil {
-		return nil, custom_error.ErrInternalServer
+		return nil, err
 	}
 	return &TokenDetails{
 		AccessToken:  aToken,
@@ -144,68 +432,164 @@ func (s *authService) GenerateTokens(userID uint, role string, tokenVersion int)
 	}, nil
 }
 
+// addOrganizationClaims gắn tổ chức đang làm việc của phiên (org_id, org_role) vào Access Token.
+// User đã bị gỡ khỏi tổ chức thì token mới không còn mang tổ chức đó
+func (s *authService) addOrganizationClaims(ctx context.Context, userID uint, session *models.Session, claims jwt.MapClaims) {
+	if session.OrganizationID == nil {
+		return
+	}
+
+	membership, err := s.orgRepo.FindMembership(ctx, *session.OrganizationID, userID)
+	if err != nil {
+		return
+	}
+	claims["org_id"] = membership.OrganizationID
+	claims["org_role"] = membership.Role
+}
+
+// signClaims ký JWT bằng khoá đang hoạt động của hệ thống
+func (s *authService) signClaims(claims jwt.MapClaims) (string, error) {
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		logger.Error("Lỗi ký JWT", zap.Error(err))
+		return "", custom_error.ErrInternalServer
+	}
+	return token, nil
+}
+
+// parseClaims giải mã, kiểm tra chữ ký/hạn dùng và đúng loại token (token_type)
+func (s *authService) parseClaims(tokenString, tokenType string) (jwt.MapClaims, error) {
+	claims, err := s.keys.Parse(tokenString)
+	if err != nil || claims["token_type"] != tokenType {
+		return nil, custom_error.ErrUnauthorized
+	}
+	return claims, nil
+}
+
 // RefreshToken giải mã token cũ và cấp phát token mới
 func (s *authService) RefreshToken(ctx context.Context, tokenString string) (*TokenDetails, error) {
-	// 1. Giải mã và kiểm tra tính hợp lệ của Refresh Token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.secret), nil
-	})
-	if err != nil || !token.Valid {
+	// 1. Giải mã, kiểm tra tính hợp lệ và đúng loại Refresh Token
+	claims, err := s.parseClaims(tokenString, "refresh")
+	if err != nil {
 		return nil, custom_error.New(401, "ERR_INVALID_REFRESH", "Refresh token không hợp lệ hoặc đã hết hạn")
 	}
 
-	// 2. Trích xuất thông tin user_id từ token
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || claims["token_type"] != "refresh" {
-		return nil, custom_error.New(401, "ERR_INVALID_TOKEN_TYPE", "Token không phải là refresh token")
+	// 2 -> 5. Kiểm tra phiên và xoay vòng jti. Refresh token cấp cho OAuth client không dùng được ở đây
+	user, session, err := rotateRefreshSession(ctx, s.repo, s.sessionRepo, claims, "")
+	if err != nil {
+		return nil, err
 	}
 
+	// 6. Nếu mọi thứ OK, tạo cặp Token mới dựa vào ID và Role của User
+	return s.GenerateTokens(ctx, user, session)
+}
+
+// rotateRefreshSession kiểm tra Refresh Token (đã verify chữ ký) và xoay vòng jti của phiên.
+// clientID phải khớp với OAuth client được cấp token (rỗng với đăng nhập trực tiếp)
+func rotateRefreshSession(
+	ctx context.Context,
+	repo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	claims jwt.MapClaims,
+	clientID string,
+) (*models.User, *models.Session, error) {
 	// Lưu ý: jwt lưu số dưới dạng float64, nên phải ép kiểu cẩn thận
-	userIDFloat, ok := claims["user_id"].(float64)
-	if !ok {
-		return nil, custom_error.ErrUnauthorized
+	userIDFloat, okID := claims["user_id"].(float64)
+	sessionIDFloat, okSession := claims["session_id"].(float64)
+	tokenVersionFloat, okVer := claims["token_version"].(float64)
+	tokenID, okJTI := claims["jti"].(string)
+	familyID, okFamily := claims["family_id"].(string)
+	tokenClientID, _ := claims["client_id"].(string)
+	if !okID || !okSession || !okVer || !okJTI || !okFamily || tokenClientID != clientID {
+		return nil, nil, custom_error.ErrUnauthorized
 	}
 
 	userID := uint(userIDFloat)
 
-	// 3. Kiểm tra xem User này còn tồn tại trong DB không
-	user, err := s.repo.FindByID(ctx, userID)
+	// Kiểm tra xem User này còn tồn tại trong DB không
+	user, err := repo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, custom_error.ErrUserNotFound
+		return nil, nil, custom_error.ErrUserNotFound
+	}
+	if user.TokenVersion != int(tokenVersionFloat) {
+		return nil, nil, custom_error.ErrUnauthorized
+	}
+	if user.IsSuspended() {
+		return nil, nil, custom_error.ErrAccountSuspended
 	}
 
-	// 4. Nếu mọi thứ OK, tạo cặp Token mới dựa vào ID và Role của User
-	return s.GenerateTokens(user.ID, user.Role, user.TokenVersion)
-}
+	// Phiên của thiết bị phải còn hiệu lực (chưa đăng xuất / chưa bị thu hồi)
+	session, err := sessionRepo.FindByID(ctx, uint(sessionIDFloat))
+	if err != nil || session.UserID != user.ID || session.FamilyID != familyID || session.ClientID != clientID || !session.IsActive() {
+		return nil, nil, custom_error.ErrSessionRevoked
+	}
 
-func (s *authService) RevokeToken(ctx context.Context, userID uint) error {
-	user, err := s.repo.FindByID(ctx, userID)
-	if err != nil {
-		return custom_error.ErrUserNotFound
+	// Xoay vòng: chỉ jti mới nhất của họ token được chấp nhận.
+	// Token cũ bị trình lại nghĩa là có 2 bên cùng giữ nó -> thu hồi cả họ
+	if tokenID != session.RefreshTokenID {
+		return nil, nil, revokeReusedFamily(ctx, sessionRepo, session, tokenID)
 	}
-	// Tăng TokenVersion khiến mọi JWT hiện tại trở thành vô nghĩa
-	user.TokenVersion += 1
-	return s.repo.Update(ctx, user)
-}
 
-func (s *authService) ForgotPassword(ctx context.Context, email string) error {
-	user, err := s.repo.FindByEmail(ctx, email)
+	now := time.Now()
+	session.RefreshTokenID = uuid.NewString()
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(refreshTTL()) // Gia hạn phiên theo kiểu trượt (sliding)
+
+	rotated, err := sessionRepo.RotateRefreshToken(ctx, session, tokenID)
 	if err != nil {
-		return nil
+		return nil, nil, custom_error.ErrInternalServer
+	}
+	if !rotated {
+		// Một request khác đã xoay token này trước chúng ta -> cũng là dấu hiệu tái sử dụng
+		return nil, nil, revokeReusedFamily(ctx, sessionRepo, session, tokenID)
 	}
 
-	// Tạo Token ngẫu nhiên bằng UUID
-	otpCode := utils.GenerateOTP()
-	expiry := time.Now().Add(15 * time.Minute)
+	return user, session, nil
+}
 
-	user.ResetPasswordOTP = &otpCode
-	user.ResetPasswordExpires = &expiry
+// revokeReusedFamily thu hồi toàn bộ họ token và ghi nhận sự kiện bảo mật
+func revokeReusedFamily(ctx context.Context, sessionRepo repositories.SessionRepository, session *models.Session, reusedTokenID string) error {
+	logger.Warn("Phát hiện tái sử dụng Refresh Token, thu hồi toàn bộ họ token",
+		zap.String("event", "refresh_token_reuse"),
+		zap.Uint("user_id", session.UserID),
+		zap.Uint("session_id", session.ID),
+		zap.String("family_id", session.FamilyID),
+		zap.String("client_id", session.ClientID),
+		zap.String("reused_jti", reusedTokenID),
+	)
+
+	if err := sessionRepo.Revoke(ctx, session.ID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return custom_error.ErrRefreshReused
+}
 
-	if err := s.repo.Update(ctx, user); err != nil {
+// RevokeToken chỉ thu hồi phiên hiện tại, các thiết bị khác vẫn giữ đăng nhập
+func (s *authService) RevokeToken(ctx context.Context, userID uint, sessionID uint) error {
+	return revokeSession(ctx, s.sessionRepo, userID, sessionID)
+}
+
+// revokeSession thu hồi phiên của user, dùng chung với /oauth/revoke
+func revokeSession(ctx context.Context, sessionRepo repositories.SessionRepository, userID uint, sessionID uint) error {
+	session, err := sessionRepo.FindByID(ctx, sessionID)
+	if err != nil || session.UserID != userID {
+		return custom_error.ErrSessionNotFound
+	}
+	if err := sessionRepo.Revoke(ctx, session.ID); err != nil {
 		return custom_error.ErrInternalServer
 	}
+	return nil
+}
 
-	utils.RunInBackground(func() {
+// ForgotPassword gửi mã OTP đặt lại mật khẩu. Luôn trả về thành công dù email có tồn tại hay không
+// (kể cả khi bị giới hạn tần suất) để không lộ danh sách tài khoản
+func (s *authService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	return s.issueResetOTP(ctx, user, func(otpCode string) {
 		subject := "🔑 Your Password Reset Code"
 
 		// Bơm mã OTP vào template reset_password.html
@@ -222,30 +606,97 @@ func (s *authService) ForgotPassword(ctx context.Context, email string) error {
 			logger.Error("Lỗi gửi email khôi phục", zap.Error(err))
 		}
 	})
+}
+
+// issueResetOTP sinh mã OTP đặt lại mật khẩu mới (thay mã cũ) và giao cho deliver gửi ngầm qua Worker Pool.
+// Dùng chung giới hạn tần suất cho mọi kênh nhận mã (email, SMS)
+func (s *authService) issueResetOTP(ctx context.Context, user *models.User, deliver func(otpCode string)) error {
+	if user.ResetOTPSentAt != nil && time.Since(*user.ResetOTPSentAt) < resetOTPResendInterval {
+		return nil
+	}
+
+	otpCode, err := utils.GenerateOTP()
+	if err != nil {
+		logger.Error("Không thể sinh mã OTP", zap.Error(err))
+		return custom_error.ErrInternalServer
+	}
+
+	// Mã chỉ có 6 chữ số nên băm bằng thuật toán chậm của mật khẩu, lộ DB cũng không dò ngược kịp trước khi hết hạn
+	otpHash, err := s.hasher.Hash(otpCode)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	now := time.Now()
+	expiry := now.Add(resetOTPTTL)
+	user.ResetOTPHash = &otpHash
+	user.ResetOTPAttempts = 0
+	user.ResetOTPSentAt = &now
+	user.ResetPasswordExpires = &expiry
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	utils.RunInBackground(func() {
+		deliver(otpCode)
+	})
 
 	return nil
 }
 
-func (s *authService) ResetPassword(ctx context.Context, OTP string, newPassword string) error {
-	user, err := s.repo.FindByResetOTP(ctx, OTP)
+// ResetPassword đặt lại mật khẩu bằng email + mã OTP. Mỗi mã chỉ được nhập sai resetOTPMaxAttempts lần,
+// sau đó bị huỷ và user phải yêu cầu mã mới
+func (s *authService) ResetPassword(ctx context.Context, email, otp, newPassword string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
 		return custom_error.ErrInvalidOTP
 	}
+	return s.resetWithOTP(ctx, user, otp, newPassword)
+}
+
+// resetWithOTP kiểm tra mã OTP đặt lại mật khẩu của user (đã tra theo email hoặc số điện thoại) rồi đổi mật khẩu
+func (s *authService) resetWithOTP(ctx context.Context, user *models.User, otp, newPassword string) error {
+	if user.ResetOTPHash == nil {
+		return custom_error.ErrInvalidOTP
+	}
 
 	// Kiểm tra hết hạn
 	if user.ResetPasswordExpires == nil || user.ResetPasswordExpires.Before(time.Now()) {
 		return custom_error.ErrOTPExpired
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	// Giữ chỗ lượt thử trước khi so mã để các request song song không vượt quá giới hạn
+	allowed, err := s.repo.ConsumeResetAttempt(ctx, user.ID, resetOTPMaxAttempts)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if !allowed {
+		return custom_error.ErrOTPAttemptsExceeded
+	}
+
+	if match, _, err := s.hasher.Verify(otp, *user.ResetOTPHash); err != nil || !match {
+		return custom_error.ErrInvalidOTP
+	}
+
+	if err := s.policy.Validate(ctx, "new_password", newPassword, user); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
 	if err != nil {
 		return custom_error.ErrInternalServer
 	}
 
-	user.Password = string(hashedPassword)
-	user.ResetPasswordOTP = nil // Xóa token sau khi dùng
+	user.Password = hashedPassword
+	user.ResetOTPHash = nil // Xóa mã sau khi dùng
+	user.ResetOTPAttempts = 0
 	user.ResetPasswordExpires = nil
 	user.TokenVersion += 1
 
-	return s.repo.Update(ctx, user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.policy.Remember(ctx, user.ID, hashedPassword)
+	return nil
 }
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	emailChangeTTL         = 24 * time.Hour
+	emailRevertTTL         = 7 * 24 * time.Hour // Chủ email cũ có thời gian phát hiện tài khoản bị chiếm
+	defaultEmailChangePath = "/email-change"    // Trang Frontend, trang này gọi POST /auth/email-change/confirm|revert
+)
+
+// RequestEmailChange bắt đầu đổi email: gửi link xác nhận tới email mới và link hoàn tác tới email cũ.
+// Email đăng nhập chỉ thay đổi sau khi link xác nhận được bấm.
+// Sai mật khẩu được tính vào bộ đếm khoá tài khoản giống Reauthenticate
+func (s *authService) RequestEmailChange(ctx context.Context, userID uint, password, newEmail string, client ClientInfo) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return custom_error.ErrUserNotFound
+	}
+
+	if err := s.guard.check(ctx, user.ID, client.IP); err != nil {
+		return err
+	}
+	if match, _, err := s.hasher.Verify(password, user.Password); err != nil || !match {
+		s.guard.recordFailure(ctx, user, client.IP)
+		return custom_error.ErrWrongPassword
+	}
+	s.guard.reset(ctx, user.ID, client.IP)
+
+	// Email vừa được đổi và chủ email cũ còn có thể hoàn tác: không cho đổi tiếp,
+	// tránh kẻ chiếm tài khoản đổi liên tiếp để vô hiệu link hoàn tác đã gửi cho chủ thật
+	if user.PendingEmail == nil && user.EmailRevertExpiresAt != nil && user.EmailRevertExpiresAt.After(time.Now()) {
+		return custom_error.ErrEmailRecentlyChanged
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return custom_error.ErrSameEmail
+	}
+	if _, err := s.repo.FindByEmail(ctx, newEmail); err == nil {
+		return custom_error.ErrEmailExists
+	}
+
+	// Yêu cầu mới thay thế yêu cầu cũ: link đã gửi trước đó hết hiệu lực
+	changeID, revertID := uuid.NewString(), uuid.NewString()
+	user.PendingEmail = &newEmail
+	user.EmailChangeTokenID = &changeID
+	user.EmailRevertTokenID = &revertID
+
+	now := time.Now()
+	revertExpiresAt := now.Add(emailRevertTTL)
+	user.EmailRevertExpiresAt = &revertExpiresAt
+	confirmToken, err := s.signClaims(jwt.MapClaims{
+		"token_type": "email_change",
+		"user_id":    user.ID,
+		"email":      newEmail,
+		"jti":        changeID,
+		"exp":        now.Add(emailChangeTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	revertToken, err := s.signClaims(jwt.MapClaims{
+		"token_type": "email_revert",
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"jti":        revertID,
+		"exp":        revertExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	baseURL := config.AppConfig.Auth.EmailChangeURL
+	if baseURL == "" {
+		baseURL = config.AppConfig.Server.Domain + defaultEmailChangePath
+	}
+	s.sendEmailChangeMails(user.Email, newEmail,
+		baseURL+"/confirm?token="+url.QueryEscape(confirmToken),
+		baseURL+"/revert?token="+url.QueryEscape(revertToken),
+	)
+	return nil
+}
+
+// ConfirmEmailChange đổi sang email mới. Email mới coi như đã xác thực và mọi phiên đăng nhập bị thu hồi
+func (s *authService) ConfirmEmailChange(ctx context.Context, token string) error {
+	user, newEmail, tokenID, err := s.userFromEmailChangeToken(ctx, token, "email_change")
+	if err != nil {
+		return err
+	}
+	// Chỉ link của yêu cầu gần nhất còn hiệu lực
+	if user.EmailChangeTokenID == nil || *user.EmailChangeTokenID != tokenID || user.PendingEmail == nil || *user.PendingEmail != newEmail {
+		return custom_error.ErrInvalidEmailChangeToken
+	}
+
+	// Có thể một tài khoản khác đã đăng ký email này trong lúc chờ xác nhận
+	if _, err := s.repo.FindByEmail(ctx, newEmail); err == nil {
+		return custom_error.ErrEmailExists
+	}
+
+	now := time.Now()
+	user.Email = newEmail
+	user.EmailVerifiedAt = &now
+	user.PendingEmail = nil
+	user.EmailChangeTokenID = nil
+	return s.saveEmailSwap(ctx, user)
+}
+
+// RevertEmailChange được chủ email cũ dùng khi không phải mình yêu cầu đổi email:
+// huỷ yêu cầu đang chờ hoặc trả lại email cũ nếu đã đổi, đồng thời đăng xuất mọi thiết bị
+func (s *authService) RevertEmailChange(ctx context.Context, token string) error {
+	user, oldEmail, tokenID, err := s.userFromEmailChangeToken(ctx, token, "email_revert")
+	if err != nil {
+		return err
+	}
+	if user.EmailRevertTokenID == nil || *user.EmailRevertTokenID != tokenID {
+		return custom_error.ErrInvalidEmailChangeToken
+	}
+
+	if user.Email != oldEmail {
+		if existing, err := s.repo.FindByEmail(ctx, oldEmail); err == nil && existing.ID != user.ID {
+			return custom_error.ErrEmailExists
+		}
+		now := time.Now()
+		user.Email = oldEmail
+		user.EmailVerifiedAt = &now
+	}
+
+	user.PendingEmail = nil
+	user.EmailChangeTokenID = nil
+	user.EmailRevertTokenID = nil
+	user.EmailRevertExpiresAt = nil
+	return s.saveEmailSwap(ctx, user)
+}
+
+// saveEmailSwap lưu email mới và thu hồi toàn bộ phiên đăng nhập.
+// Unique index idx_email_unique chặn trường hợp hai tài khoản cùng lấy một email trong cùng thời điểm
+func (s *authService) saveEmailSwap(ctx context.Context, user *models.User) error {
+	user.TokenVersion += 1
+	if err := s.repo.Update(ctx, user); err != nil {
+		if _, findErr := s.repo.FindByEmail(ctx, user.Email); findErr == nil {
+			return custom_error.ErrEmailExists
+		}
+		return custom_error.ErrInternalServer
+	}
+
+	if err := s.sessionRepo.RevokeAllByUser(ctx, user.ID, 0); err != nil {
+		logger.Error("Không thể thu hồi phiên sau khi đổi email", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	return nil
+}
+
+// userFromEmailChangeToken giải mã link đổi/hoàn tác email, trả về user, email và jti ghi trong token
+func (s *authService) userFromEmailChangeToken(ctx context.Context, token, tokenType string) (*models.User, string, string, error) {
+	claims, err := s.parseClaims(token, tokenType)
+	if err != nil {
+		return nil, "", "", custom_error.ErrInvalidEmailChangeToken
+	}
+
+	userIDFloat, okID := claims["user_id"].(float64)
+	email, okEmail := claims["email"].(string)
+	tokenID, okJTI := claims["jti"].(string)
+	if !okID || !okEmail || !okJTI {
+		return nil, "", "", custom_error.ErrInvalidEmailChangeToken
+	}
+
+	user, err := s.repo.FindByID(ctx, uint(userIDFloat))
+	if err != nil {
+		return nil, "", "", custom_error.ErrInvalidEmailChangeToken
+	}
+	return user, email, tokenID, nil
+}
+
+// sendEmailChangeMails gửi ngầm link xác nhận tới email mới và thông báo kèm link hoàn tác tới email cũ
+func (s *authService) sendEmailChangeMails(oldEmail, newEmail, confirmLink, revertLink string) {
+	utils.RunInBackground(func() {
+		body, err := templates.Render("email_change_confirm.html", map[string]interface{}{
+			"Email": newEmail,
+			"Link":  confirmLink,
+		})
+		if err != nil {
+			logger.Error("Lỗi render template xác nhận đổi email", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(newEmail, "✉️ Confirm your new email address", body); err != nil {
+			logger.Error("Lỗi gửi email xác nhận đổi email", zap.Error(err))
+		}
+	})
+
+	utils.RunInBackground(func() {
+		body, err := templates.Render("email_change_notice.html", map[string]interface{}{
+			"Email":    oldEmail,
+			"NewEmail": newEmail,
+			"Link":     revertLink,
+		})
+		if err != nil {
+			logger.Error("Lỗi render template thông báo đổi email", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(oldEmail, "⚠️ Your email address is being changed", body); err != nil {
+			logger.Error("Lỗi gửi email thông báo đổi email", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	emailVerifyTTL     = 24 * time.Hour
+	verificationResend = time.Minute // Khoảng cách tối thiểu giữa 2 lần gửi lại email xác thực
+	defaultVerifyPath  = "/api/v1/auth/verify-email"
+)
+
+// VerifyEmail xác nhận địa chỉ email từ token đã ký trong link
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := s.parseClaims(token, "email_verify")
+	if err != nil {
+		return custom_error.ErrInvalidVerifyToken
+	}
+
+	userIDFloat, okID := claims["user_id"].(float64)
+	email, okEmail := claims["email"].(string)
+	if !okID || !okEmail {
+		return custom_error.ErrInvalidVerifyToken
+	}
+
+	user, err := s.repo.FindByID(ctx, uint(userIDFloat))
+	// Token gắn với email lúc gửi: nếu email đã đổi thì link cũ vô hiệu
+	if err != nil || user.Email != email {
+		return custom_error.ErrInvalidVerifyToken
+	}
+
+	// Bấm link nhiều lần vẫn coi như thành công
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	s.sendWelcomeEmail(user.Email)
+	return nil
+}
+
+// ResendVerification luôn trả về thành công để không lộ email nào đã đăng ký
+func (s *authService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	// Chống spam hộp thư: bỏ qua âm thầm nếu vừa gửi xong
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < verificationResend {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// sendVerificationEmail ký link xác thực và gửi ngầm qua Worker Pool
+func (s *authService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := s.signClaims(jwt.MapClaims{
+		"token_type": "email_verify",
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"exp":        time.Now().Add(emailVerifyTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.VerificationSentAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	baseURL := config.AppConfig.Auth.VerifyEmailURL
+	if baseURL == "" {
+		baseURL = config.AppConfig.Server.Domain + defaultVerifyPath
+	}
+	link := baseURL + "?token=" + url.QueryEscape(token)
+	email := user.Email
+
+	utils.RunInBackground(func() {
+		subject := "✉️ Verify your email address"
+		body, err := templates.Render("verify_email.html", map[string]interface{}{
+			"Email": email,
+			"Link":  link,
+		})
+
+		if err != nil {
+			logger.Error("Lỗi render template verify email", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(email, subject, body); err != nil {
+			logger.Error("Lỗi gửi email xác thực", zap.Error(err))
+		}
+	})
+
+	return nil
+}
+
+func (s *authService) sendWelcomeEmail(email string) {
+	utils.RunInBackground(func() {
+		subject := "🎉 Welcome to [YourApp]!"
+		body, err := templates.Render("welcome.html", map[string]interface{}{
+			"Email": email,
+			"Link":  config.AppConfig.Server.Domain,
+		})
+
+		if err != nil {
+			logger.Error("Lỗi render template welcome", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(email, subject, body); err != nil {
+			logger.Error("Lỗi gửi email chào mừng", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// impersonationTTL: token giả lập không có Refresh Token, hết hạn thì admin phải giả lập lại
+const impersonationTTL = 15 * time.Minute
+
+// ImpersonationToken là Access Token giúp admin xem hệ thống dưới danh nghĩa một user
+type ImpersonationToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // giây
+	UserID      uint   `json:"user_id"`
+}
+
+// Impersonate cấp Access Token ngắn hạn của user kèm claim act (RFC 8693) ghi danh admin thực hiện.
+// Token gắn với phiên của admin: admin đăng xuất hoặc mất quyền thì token giả lập cũng hết hiệu lực
+func (s *authService) Impersonate(ctx context.Context, actorID, sessionID, userID uint) (*ImpersonationToken, error) {
+	if actorID == userID {
+		return nil, custom_error.ErrCannotImpersonateSelf
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	actor, err := s.repo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, custom_error.ErrUnauthorized
+	}
+
+	// Giống AdminUpdateUser: không được giả lập user có quyền vượt quá quyền của mình
+	actorRole, err := s.roleRepo.FindByName(ctx, actor.Role)
+	if err != nil {
+		return nil, custom_error.ErrForbidden
+	}
+	userRole, err := s.roleRepo.FindByName(ctx, user.Role)
+	if err != nil || !coversPermissions(actorRole, userRole) {
+		return nil, custom_error.ErrForbidden
+	}
+
+	claims := jwt.MapClaims{
+		"token_type":    "access",
+		"user_id":       user.ID,
+		"role":          user.Role,
+		"token_version": user.TokenVersion,
+		"session_id":    sessionID,
+		"act":           map[string]interface{}{"sub": strconv.FormatUint(uint64(actor.ID), 10)},
+		"exp":           time.Now().Add(impersonationTTL).Unix(),
+	}
+	if err := addPermissionClaims(ctx, s.roleRepo, user.Role, claims); err != nil {
+		return nil, err
+	}
+
+	// Admin đang làm việc trong một tổ chức thì giữ nguyên tổ chức đó (user chắc chắn là thành viên vì FindByID đã lọc theo tenant)
+	if organizationID, ok := repositories.TenantFromContext(ctx); ok {
+		s.addOrganizationClaims(ctx, user.ID, &models.Session{OrganizationID: &organizationID}, claims)
+	}
+
+	token, err := s.signClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Admin bắt đầu giả lập user",
+		zap.Uint("actor_id", actor.ID),
+		zap.Uint("user_id", user.ID),
+		zap.Uint("session_id", sessionID),
+	)
+
+	return &ImpersonationToken{
+		AccessToken: token,
+		ExpiresIn:   int(impersonationTTL.Seconds()),
+		UserID:      user.ID,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	magicLinkTTL         = 15 * time.Minute
+	defaultMagicLinkPath = "/magic-link"
+)
+
+// MagicLinkURL trả về link tới trang Frontend nhận token rồi gọi POST /auth/magic-link/consume.
+// Link phải trỏ về trang chứ không trỏ thẳng vào API: trình quét link của hòm thư
+// mở GET trước người dùng và sẽ tiêu mất link dùng 1 lần
+func MagicLinkURL(token string) string {
+	baseURL := config.AppConfig.Auth.MagicLinkURL
+	if baseURL == "" {
+		baseURL = config.AppConfig.Server.Domain + defaultMagicLinkPath
+	}
+	return baseURL + "?token=" + url.QueryEscape(token)
+}
+
+// RequestMagicLink gửi link đăng nhập không cần mật khẩu qua email. Giống ForgotPassword:
+// luôn trả về thành công dù email có tồn tại hay không, kể cả khi bị giới hạn tần suất
+func (s *authService) RequestMagicLink(ctx context.Context, email string) error {
+	user, err := s.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	if user.MagicLinkSentAt != nil && time.Since(*user.MagicLinkSentAt) < resetOTPResendInterval {
+		return nil
+	}
+
+	// Link mới thay thế link cũ: chỉ link gửi gần nhất dùng được, và chỉ dùng được một lần
+	now := time.Now()
+	tokenID := uuid.NewString()
+	token, err := s.signClaims(jwt.MapClaims{
+		"token_type": "magic_link",
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"jti":        tokenID,
+		"exp":        now.Add(magicLinkTTL).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	user.MagicLinkTokenID = &tokenID
+	user.MagicLinkSentAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	link := MagicLinkURL(token)
+	to := user.Email
+
+	utils.RunInBackground(func() {
+		body, err := templates.Render("magic_link.html", map[string]interface{}{
+			"Email": to,
+			"Link":  link,
+		})
+		if err != nil {
+			logger.Error("Lỗi render template magic link", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(to, "🔗 Your sign-in link", body); err != nil {
+			logger.Error("Lỗi gửi email magic link", zap.Error(err))
+		}
+	})
+
+	return nil
+}
+
+// ConsumeMagicLink đổi link đăng nhập lấy cặp token như Login.
+// Bấm được link nghĩa là sở hữu email, nên email được coi là đã xác thực; tài khoản bật 2FA vẫn phải nhập mã
+func (s *authService) ConsumeMagicLink(ctx context.Context, token string, client ClientInfo) (*TokenDetails, *MFAChallenge, error) {
+	claims, err := s.parseClaims(token, "magic_link")
+	if err != nil {
+		return nil, nil, custom_error.ErrInvalidMagicLink
+	}
+
+	userIDFloat, okID := claims["user_id"].(float64)
+	email, okEmail := claims["email"].(string)
+	tokenID, okJTI := claims["jti"].(string)
+	if !okID || !okEmail || !okJTI {
+		return nil, nil, custom_error.ErrInvalidMagicLink
+	}
+
+	user, err := s.repo.FindByID(ctx, uint(userIDFloat))
+	// Email đã đổi sau khi gửi link thì link cũ vô hiệu
+	if err != nil || user.Email != email {
+		return nil, nil, custom_error.ErrInvalidMagicLink
+	}
+
+	// Xoá jti một cách nguyên tử: hai request dùng cùng một link song song thì chỉ một request thành công
+	claimed, err := s.repo.ClaimMagicLink(ctx, user.ID, tokenID)
+	if err != nil {
+		return nil, nil, custom_error.ErrInternalServer
+	}
+	if !claimed {
+		s.history.record(user, "", client, models.LoginMethodMagicLink, custom_error.ErrInvalidMagicLink)
+		return nil, nil, custom_error.ErrInvalidMagicLink
+	}
+	user.MagicLinkTokenID = nil
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.repo.Update(ctx, user); err != nil {
+			return nil, nil, custom_error.ErrInternalServer
+		}
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := s.issueMFAChallenge(user)
+		return nil, challenge, err
+	}
+
+	tokens, err := s.startSession(ctx, user, client)
+	s.history.record(user, "", client, models.LoginMethodMagicLink, err)
+	return tokens, nil, err
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/oauth"
+	"go-core-api/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	oauthStateTTL = 10 * time.Minute
+	// Bảng ký tự cho mật khẩu ngẫu nhiên, client_id, code... sinh bằng utils.GenerateRandomString
+	alphanumericAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// OAuthStart là kết quả bước chuyển hướng sang provider
+type OAuthStart struct {
+	AuthURL string
+	// StateToken giữ state/nonce/PKCE verifier đã ký, client phải giữ lại (cookie HttpOnly) tới bước callback
+	StateToken string
+	ExpiresIn  int // giây
+}
+
+// BeginOAuth tạo state, nonce, PKCE verifier và URL đăng nhập của provider
+func (s *authService) BeginOAuth(ctx context.Context, providerName string) (*OAuthStart, error) {
+	provider, err := s.oauth.Get(providerName)
+	if err != nil {
+		return nil, custom_error.ErrOAuthProviderNotFound
+	}
+
+	req, err := oauth.NewAuthRequest()
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, req)
+	if err != nil {
+		logger.Error("Lỗi khởi tạo đăng nhập OAuth", zap.String("provider", providerName), zap.Error(err))
+		return nil, custom_error.ErrOAuthFailed
+	}
+
+	stateToken, err := s.signClaims(jwt.MapClaims{
+		"token_type":    "oauth_state",
+		"provider":      providerName,
+		"state":         req.State,
+		"nonce":         req.Nonce,
+		"code_verifier": req.CodeVerifier,
+		"exp":           time.Now().Add(oauthStateTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthStart{
+		AuthURL:    authURL,
+		StateToken: stateToken,
+		ExpiresIn:  int(oauthStateTTL.Seconds()),
+	}, nil
+}
+
+// CompleteOAuth kiểm tra state, đổi code lấy định danh và đăng nhập như luồng mật khẩu thông thường
+func (s *authService) CompleteOAuth(ctx context.Context, providerName, code, state, stateToken string, client ClientInfo) (*TokenDetails, *MFAChallenge, error) {
+	provider, err := s.oauth.Get(providerName)
+	if err != nil {
+		return nil, nil, custom_error.ErrOAuthProviderNotFound
+	}
+
+	// 1. State phải khớp với state đã ký lúc chuyển hướng (chống CSRF / login injection)
+	claims, err := s.parseClaims(stateToken, "oauth_state")
+	if err != nil {
+		return nil, nil, custom_error.ErrOAuthStateInvalid
+	}
+	expectedState, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["code_verifier"].(string)
+	if claims["provider"] != providerName || expectedState == "" ||
+		subtle.ConstantTimeCompare([]byte(expectedState), []byte(state)) != 1 {
+		return nil, nil, custom_error.ErrOAuthStateInvalid
+	}
+
+	// 2. Đổi code (kèm PKCE verifier) lấy thông tin định danh
+	identity, err := provider.Exchange(ctx, code, oauth.AuthRequest{State: expectedState, Nonce: nonce, CodeVerifier: verifier})
+	if err != nil {
+		logger.Warn("Đăng nhập OAuth thất bại", zap.String("provider", providerName), zap.Error(err))
+		return nil, nil, custom_error.ErrOAuthFailed
+	}
+
+	// 3. Tìm user đã liên kết, khớp theo email đã xác thực hoặc tạo mới
+	user, err := s.resolveOAuthUser(ctx, providerName, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 4. Tài khoản bật 2FA vẫn phải qua bước nhập mã như khi đăng nhập bằng mật khẩu
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := s.issueMFAChallenge(user)
+		return nil, challenge, err
+	}
+
+	tokens, err := s.startSession(ctx, user, client)
+	s.history.record(user, "", client, models.LoginMethodOAuth+":"+providerName, err)
+	return tokens, nil, err
+}
+
+// resolveOAuthUser trả về user ứng với định danh bên ngoài, liên kết hoặc tạo mới khi cần
+func (s *authService) resolveOAuthUser(ctx context.Context, providerName string, identity *oauth.Identity) (*models.User, error) {
+	linked, err := s.identityRepo.FindByProviderSubject(ctx, providerName, identity.Subject)
+	if err == nil {
+		user, err := s.repo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			return nil, custom_error.ErrUserNotFound
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	// Chỉ tin email mà provider đã xác thực, nếu không ai cũng có thể chiếm tài khoản bằng email của người khác
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, custom_error.ErrOAuthEmailNotVerified
+	}
+
+	user, err := s.repo.FindByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		if err := s.claimExistingAccount(ctx, user); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if user, err = s.provisionOAuthUser(ctx, identity); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, custom_error.ErrInternalServer
+	}
+
+	if err := s.identityRepo.Create(ctx, &models.Identity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	logger.Info("Đã liên kết tài khoản bên ngoài",
+		zap.Uint("user_id", user.ID),
+		zap.String("provider", providerName),
+	)
+	return user, nil
+}
+
+// claimExistingAccount liên kết vào tài khoản có sẵn cùng email.
+// Nếu email của tài khoản đó chưa từng được xác thực thì có thể người khác đã đăng ký trước bằng email này:
+// vô hiệu mật khẩu cũ và mọi phiên đang mở để chủ thật của email nắm toàn quyền
+func (s *authService) claimExistingAccount(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	password, err := s.unusablePassword()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.Password = password
+	user.TokenVersion += 1
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// provisionOAuthUser tạo tài khoản mới với email đã được provider xác thực
+func (s *authService) provisionOAuthUser(ctx context.Context, identity *oauth.Identity) (*models.User, error) {
+	password, err := s.unusablePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Email:           identity.Email,
+		EmailVerifiedAt: &now,
+		Password:        password,
+		FullName:        identity.Name,
+		Avatar:          identity.Picture,
+		Role:            models.RoleUser,
+	}
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	s.sendWelcomeEmail(user.Email)
+	return user, nil
+}
+
+// unusablePassword băm một mật khẩu ngẫu nhiên không ai biết.
+// User tạo từ đăng nhập ngoài có thể đặt mật khẩu thật qua quên mật khẩu
+func (s *authService) unusablePassword() (string, error) {
+	raw, err := utils.GenerateRandomString(32, alphanumericAlphabet)
+	if err != nil {
+		return "", custom_error.ErrInternalServer
+	}
+
+	hashed, err := s.hasher.Hash(raw)
+	if err != nil {
+		return "", custom_error.ErrInternalServer
+	}
+	return hashed, nil
+}
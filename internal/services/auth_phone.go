@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+// Mã OTP xác thực số điện thoại
+const (
+	phoneOTPTTL            = 10 * time.Minute
+	phoneOTPMaxAttempts    = 5
+	phoneOTPResendInterval = time.Minute
+)
+
+// SendPhoneOTP gửi mã xác thực qua SMS tới số điện thoại trong hồ sơ của user.
+// Đổi số điện thoại (UpdateProfile) sẽ huỷ mã đang chờ, nên mã luôn gắn với đúng số đã nhận tin
+func (s *authService) SendPhoneOTP(ctx context.Context, userID uint) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return custom_error.ErrUserNotFound
+	}
+	if user.Phone == "" {
+		return custom_error.ErrPhoneRequired
+	}
+	if user.PhoneVerifiedAt != nil {
+		return custom_error.ErrPhoneAlreadyVerified
+	}
+	if user.PhoneOTPSentAt != nil && time.Since(*user.PhoneOTPSentAt) < phoneOTPResendInterval {
+		return custom_error.ErrTooManyRequests
+	}
+
+	otpCode, err := utils.GenerateOTP()
+	if err != nil {
+		logger.Error("Không thể sinh mã OTP", zap.Error(err))
+		return custom_error.ErrInternalServer
+	}
+	otpHash, err := s.hasher.Hash(otpCode)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	now := time.Now()
+	expiry := now.Add(phoneOTPTTL)
+	user.PhoneOTPHash = &otpHash
+	user.PhoneOTPAttempts = 0
+	user.PhoneOTPSentAt = &now
+	user.PhoneOTPExpires = &expiry
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	s.sendSMS(user.Phone, fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", otpCode, int(phoneOTPTTL.Minutes())))
+	return nil
+}
+
+// VerifyPhone xác nhận mã OTP đã gửi qua SMS. Mỗi số chỉ được xác thực bởi một tài khoản
+// (unique index idx_phone_verified_unique) vì số đã xác thực được dùng để đặt lại mật khẩu
+func (s *authService) VerifyPhone(ctx context.Context, userID uint, otp string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return custom_error.ErrUserNotFound
+	}
+	if user.PhoneVerifiedAt != nil {
+		return custom_error.ErrPhoneAlreadyVerified
+	}
+	if user.PhoneOTPHash == nil {
+		return custom_error.ErrInvalidOTP
+	}
+	if user.PhoneOTPExpires == nil || user.PhoneOTPExpires.Before(time.Now()) {
+		return custom_error.ErrOTPExpired
+	}
+
+	allowed, err := s.repo.ConsumePhoneOTPAttempt(ctx, user.ID, phoneOTPMaxAttempts)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if !allowed {
+		return custom_error.ErrOTPAttemptsExceeded
+	}
+
+	if match, _, err := s.hasher.Verify(otp, *user.PhoneOTPHash); err != nil || !match {
+		return custom_error.ErrInvalidOTP
+	}
+
+	if existing, err := s.repo.FindByVerifiedPhone(ctx, user.Phone); err == nil && existing.ID != user.ID {
+		return custom_error.ErrPhoneExists
+	}
+
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	user.PhoneOTPHash = nil
+	user.PhoneOTPAttempts = 0
+	user.PhoneOTPExpires = nil
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		// Một tài khoản khác vừa xác thực cùng số trong cùng thời điểm
+		if _, findErr := s.repo.FindByVerifiedPhone(ctx, user.Phone); findErr == nil {
+			return custom_error.ErrPhoneExists
+		}
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// ForgotPasswordByPhone giống ForgotPassword nhưng gửi mã qua SMS tới số điện thoại đã xác thực.
+// Luôn trả về thành công dù số có thuộc tài khoản nào hay không
+func (s *authService) ForgotPasswordByPhone(ctx context.Context, phone string) error {
+	user, err := s.findByVerifiedPhone(ctx, phone)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	return s.issueResetOTP(ctx, user, func(otpCode string) {
+		message := fmt.Sprintf("Your password reset code is %s. It expires in %d minutes.", otpCode, int(resetOTPTTL.Minutes()))
+		if err := s.sms.Send(user.Phone, message); err != nil {
+			logger.Error("Lỗi gửi SMS khôi phục", zap.Error(err))
+		}
+	})
+}
+
+// ResetPasswordByPhone đặt lại mật khẩu bằng số điện thoại đã xác thực + mã OTP (cùng giới hạn lượt thử với email)
+func (s *authService) ResetPasswordByPhone(ctx context.Context, phone, otp, newPassword string) error {
+	user, err := s.findByVerifiedPhone(ctx, phone)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return custom_error.ErrInvalidOTP
+	}
+	return s.resetWithOTP(ctx, user, otp, newPassword)
+}
+
+// findByVerifiedPhone chuẩn hoá số rồi tra cứu, trả về nil (không lỗi) khi không có tài khoản nào đã xác thực số này
+func (s *authService) findByVerifiedPhone(ctx context.Context, phone string) (*models.User, error) {
+	normalized, err := utils.NormalizePhone(phone, config.AppConfig.SMS.DefaultCountryCode)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.FindByVerifiedPhone(ctx, normalized)
+	if err != nil {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// sendSMS gửi tin nhắn ngầm qua Worker Pool
+func (s *authService) sendSMS(to, message string) {
+	utils.RunInBackground(func() {
+		if err := s.sms.Send(to, message); err != nil {
+			logger.Error("Lỗi gửi SMS", zap.Error(err))
+		}
+	})
+}
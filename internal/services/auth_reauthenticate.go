@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/pkg/custom_error"
+)
+
+// Reauthenticate xác nhận lại mật khẩu (và mã 2FA nếu tài khoản đã bật) trên phiên đang đăng nhập,
+// sau đó cấp lại token mang auth_time mới để đi qua RequireRecentAuth.
+// Sai mật khẩu/mã được tính vào bộ đếm khoá tài khoản như khi đăng nhập, để Access Token bị lộ không dùng để dò mật khẩu
+func (s *authService) Reauthenticate(ctx context.Context, userID, sessionID uint, password, code string, client ClientInfo) (*TokenDetails, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+
+	// Chỉ phiên đăng nhập trực tiếp: token cấp cho OAuth client không được tự nâng mức xác thực
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil || session.UserID != user.ID || !session.IsActive() {
+		return nil, custom_error.ErrSessionRevoked
+	}
+	if session.ClientID != "" {
+		return nil, custom_error.ErrReauthenticationNotAllowed
+	}
+
+	if err := s.guard.check(ctx, user.ID, client.IP); err != nil {
+		return nil, err
+	}
+
+	// Tài khoản tạo qua OAuth mang mật khẩu không dùng được, cần đặt mật khẩu (forgot-password) trước
+	if match, _, err := s.hasher.Verify(password, user.Password); err != nil || !match {
+		s.guard.recordFailure(ctx, user, client.IP)
+		return nil, custom_error.ErrInvalidCredentials
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		if code == "" {
+			return nil, custom_error.ErrTwoFactorCodeRequired
+		}
+		if err := s.twoFactor.VerifyCode(ctx, user, code); err != nil {
+			if err == custom_error.ErrInvalidTwoFactorCode {
+				s.guard.recordFailure(ctx, user, client.IP)
+			}
+			return nil, err
+		}
+	}
+	s.guard.reset(ctx, user.ID, client.IP)
+
+	now := time.Now()
+	if err := s.sessionRepo.SetAuthenticatedAt(ctx, session.ID, now); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	session.AuthenticatedAt = now
+	return s.GenerateTokens(ctx, user, session)
+}
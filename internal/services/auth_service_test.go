@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func refreshClaims(session *models.Session, tokenID string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"token_type":    "refresh",
+		"user_id":       float64(session.UserID),
+		"session_id":    float64(session.ID),
+		"token_version": float64(1),
+		"jti":           tokenID,
+		"family_id":     session.FamilyID,
+	}
+}
+
+func newRefreshTestRepos(t *testing.T) (*fakeUserRepo, *fakeSessionRepo) {
+	// Xoay vòng gia hạn phiên theo refresh_expiration, để 0 thì phiên hết hạn ngay
+	previous := config.AppConfig.JWT.RefreshExpiration
+	config.AppConfig.JWT.RefreshExpiration = 7
+	t.Cleanup(func() { config.AppConfig.JWT.RefreshExpiration = previous })
+
+	users := newFakeUserRepo(&models.User{ID: 1, Email: "user@example.com", TokenVersion: 1})
+	sessions := newFakeSessionRepo(&models.Session{
+		ID:             10,
+		UserID:         1,
+		FamilyID:       "family",
+		RefreshTokenID: "jti-1",
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	return users, sessions
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	users, sessions := newRefreshTestRepos(t)
+	original := *sessions.sessions[10]
+
+	_, rotated, err := rotateRefreshSession(ctx, users, sessions, refreshClaims(&original, "jti-1"), "")
+	if err != nil {
+		t.Fatalf("xoay vòng lần đầu: %v", err)
+	}
+	if rotated.RefreshTokenID == "jti-1" {
+		t.Fatal("jti của phiên phải đổi sau khi xoay vòng")
+	}
+
+	// Token cũ bị trình lại (kẻ tấn công hoặc client thật, không biết bên nào) -> thu hồi cả họ
+	if _, _, err := rotateRefreshSession(ctx, users, sessions, refreshClaims(&original, "jti-1"), ""); !errors.Is(err, custom_error.ErrRefreshReused) {
+		t.Fatalf("trình lại jti cũ trả về %v, muốn ErrRefreshReused", err)
+	}
+	if sessions.sessions[10].IsActive() {
+		t.Fatal("phiên phải bị thu hồi khi phát hiện tái sử dụng")
+	}
+
+	// Token mới nhất của họ cũng không dùng được nữa
+	if _, _, err := rotateRefreshSession(ctx, users, sessions, refreshClaims(&original, rotated.RefreshTokenID), ""); !errors.Is(err, custom_error.ErrSessionRevoked) {
+		t.Fatalf("jti mới nhất sau khi thu hồi trả về %v, muốn ErrSessionRevoked", err)
+	}
+}
+
+func TestRefreshTokenConcurrentRotationRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	users, sessions := newRefreshTestRepos(t)
+	original := *sessions.sessions[10]
+
+	// Request khác đã xoay jti-1 sau khi request này đọc phiên: UPDATE có điều kiện không khớp dòng nào
+	sessions.sessions[10].RefreshTokenID = "jti-other"
+	stale := &staleSessionRepo{fakeSessionRepo: sessions, snapshot: original}
+
+	if _, _, err := rotateRefreshSession(ctx, users, stale, refreshClaims(&original, "jti-1"), ""); !errors.Is(err, custom_error.ErrRefreshReused) {
+		t.Fatalf("xoay vòng thua cuộc đua trả về %v, muốn ErrRefreshReused", err)
+	}
+	if sessions.sessions[10].IsActive() {
+		t.Fatal("phiên phải bị thu hồi khi hai request cùng xoay một jti")
+	}
+}
+
+// staleSessionRepo trả về bản phiên đọc trước khi request khác kịp xoay vòng
+type staleSessionRepo struct {
+	*fakeSessionRepo
+	snapshot models.Session
+}
+
+func (r *staleSessionRepo) FindByID(context.Context, uint) (*models.Session, error) {
+	copied := r.snapshot
+	return &copied, nil
+}
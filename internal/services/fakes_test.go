@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	config.AppConfig = &config.Config{}
+	os.Exit(m.Run())
+}
+
+// Các repository giả lưu dữ liệu trong map, chỉ cài đủ hành vi mà service cần.
+// Method không dùng tới trả về zero value
+
+type fakeUserRepo struct {
+	users map[uint]*models.User
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: make(map[uint]*models.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) FindByID(_ context.Context, id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(_ context.Context, id uint) error {
+	delete(r.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) Purge(ctx context.Context, id uint) error {
+	return r.Delete(ctx, id)
+}
+
+func (r *fakeUserRepo) Create(context.Context, *models.User) error { return nil }
+func (r *fakeUserRepo) FindByEmail(context.Context, string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (r *fakeUserRepo) FindByVerifiedPhone(context.Context, string) (*models.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (r *fakeUserRepo) GetList(context.Context, utils.Pagination) ([]models.User, int64, error) {
+	return nil, 0, nil
+}
+func (r *fakeUserRepo) ConsumeResetAttempt(context.Context, uint, int) (bool, error) {
+	return false, nil
+}
+func (r *fakeUserRepo) ConsumePhoneOTPAttempt(context.Context, uint, int) (bool, error) {
+	return false, nil
+}
+func (r *fakeUserRepo) ClaimMagicLink(context.Context, uint, string) (bool, error) {
+	return false, nil
+}
+func (r *fakeUserRepo) ClaimTOTPStep(context.Context, uint, int64) (bool, error) {
+	return false, nil
+}
+
+type fakeRoleRepo struct {
+	roles map[string]*models.Role
+}
+
+func newFakeRoleRepo(roles ...*models.Role) *fakeRoleRepo {
+	repo := &fakeRoleRepo{roles: make(map[string]*models.Role)}
+	for _, role := range roles {
+		repo.roles[role.Name] = role
+	}
+	return repo
+}
+
+func (r *fakeRoleRepo) FindByName(_ context.Context, name string) (*models.Role, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepo) Create(context.Context, *models.Role) error { return nil }
+func (r *fakeRoleRepo) FindByID(context.Context, uint) (*models.Role, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (r *fakeRoleRepo) List(context.Context) ([]models.Role, error)       { return nil, nil }
+func (r *fakeRoleRepo) Update(context.Context, *models.Role) error        { return nil }
+func (r *fakeRoleRepo) Delete(context.Context, uint) error                { return nil }
+func (r *fakeRoleRepo) CountUsers(context.Context, string) (int64, error) { return 0, nil }
+func (r *fakeRoleRepo) SyncPermissions(context.Context, []models.Permission) error {
+	return nil
+}
+func (r *fakeRoleRepo) FindPermissions(context.Context, []string) ([]models.Permission, error) {
+	return nil, nil
+}
+func (r *fakeRoleRepo) ListPermissions(context.Context) ([]models.Permission, error) {
+	return nil, nil
+}
+
+type fakeThrottleRepo struct {
+	entries map[string]*models.LoginThrottle
+}
+
+func newFakeThrottleRepo() *fakeThrottleRepo {
+	return &fakeThrottleRepo{entries: make(map[string]*models.LoginThrottle)}
+}
+
+func (r *fakeThrottleRepo) Find(_ context.Context, key string) (*models.LoginThrottle, error) {
+	entry, ok := r.entries[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (r *fakeThrottleRepo) RecordFailure(_ context.Context, key string, userID uint, _ time.Duration) (*models.LoginThrottle, error) {
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &models.LoginThrottle{Key: key, UserID: userID}
+		r.entries[key] = entry
+	}
+	entry.Failures++
+	entry.LastFailedAt = time.Now()
+	copied := *entry
+	return &copied, nil
+}
+
+func (r *fakeThrottleRepo) Lock(_ context.Context, key string, until time.Time) error {
+	if entry, ok := r.entries[key]; ok {
+		entry.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *fakeThrottleRepo) Reset(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(r.entries, key)
+	}
+	return nil
+}
+
+func (r *fakeThrottleRepo) ResetByUser(context.Context, uint) error { return nil }
+
+type fakeSessionRepo struct {
+	sessions map[uint]*models.Session
+}
+
+func newFakeSessionRepo(sessions ...*models.Session) *fakeSessionRepo {
+	repo := &fakeSessionRepo{sessions: make(map[uint]*models.Session)}
+	for _, session := range sessions {
+		repo.sessions[session.ID] = session
+	}
+	return repo
+}
+
+func (r *fakeSessionRepo) FindByID(_ context.Context, id uint) (*models.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// RotateRefreshToken chỉ ghi khi jti trong "database" vẫn là oldTokenID, giống điều kiện WHERE của repository thật
+func (r *fakeSessionRepo) RotateRefreshToken(_ context.Context, session *models.Session, oldTokenID string) (bool, error) {
+	stored, ok := r.sessions[session.ID]
+	if !ok || stored.RefreshTokenID != oldTokenID {
+		return false, nil
+	}
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return true, nil
+}
+
+func (r *fakeSessionRepo) Revoke(_ context.Context, id uint) error {
+	if session, ok := r.sessions[id]; ok {
+		now := time.Now()
+		session.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) Create(context.Context, *models.Session) error { return nil }
+func (r *fakeSessionRepo) ListActiveByUser(context.Context, uint) ([]models.Session, error) {
+	return nil, nil
+}
+func (r *fakeSessionRepo) Update(context.Context, *models.Session) error     { return nil }
+func (r *fakeSessionRepo) RevokeAllByUser(context.Context, uint, uint) error { return nil }
+func (r *fakeSessionRepo) RevokeAllByClient(context.Context, string) error   { return nil }
+func (r *fakeSessionRepo) SetOrganization(context.Context, uint, *uint) error {
+	return nil
+}
+func (r *fakeSessionRepo) SetAuthenticatedAt(context.Context, uint, time.Time) error {
+	return nil
+}
+
+type fakeOrganizationRepo struct {
+	memberships map[[2]uint]*models.Membership
+}
+
+func newFakeOrganizationRepo(memberships ...*models.Membership) *fakeOrganizationRepo {
+	repo := &fakeOrganizationRepo{memberships: make(map[[2]uint]*models.Membership)}
+	for _, membership := range memberships {
+		repo.memberships[[2]uint{membership.OrganizationID, membership.UserID}] = membership
+	}
+	return repo
+}
+
+func (r *fakeOrganizationRepo) FindMembership(_ context.Context, organizationID, userID uint) (*models.Membership, error) {
+	membership, ok := r.memberships[[2]uint{organizationID, userID}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return membership, nil
+}
+
+func (r *fakeOrganizationRepo) Create(context.Context, *models.Organization, *models.Membership) error {
+	return nil
+}
+func (r *fakeOrganizationRepo) FindByID(context.Context, uint) (*models.Organization, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (r *fakeOrganizationRepo) AddMember(context.Context, *models.Membership) error { return nil }
+func (r *fakeOrganizationRepo) ListMembers(context.Context, uint) ([]models.Membership, error) {
+	return nil, nil
+}
+func (r *fakeOrganizationRepo) ListByUser(context.Context, uint) ([]models.Membership, error) {
+	return nil, nil
+}
+func (r *fakeOrganizationRepo) UpdateMemberRole(context.Context, *models.Membership) error {
+	return nil
+}
+func (r *fakeOrganizationRepo) RemoveMember(context.Context, uint, uint) error { return nil }
+func (r *fakeOrganizationRepo) CountOwners(context.Context, uint) (int64, error) {
+	return 0, nil
+}
+
+// roleWith tạo role có đúng danh sách quyền truyền vào
+func roleWith(name string, permissions ...string) *models.Role {
+	role := &models.Role{Name: name}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, models.Permission{Name: permission})
+	}
+	return role
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/hasher"
+	"go-core-api/pkg/jwtkeys"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInvitationDays = 7
+	maxInvitationDays     = 30
+	invitationResend      = time.Minute // Khoảng cách tối thiểu giữa 2 lần gửi lại cùng một lời mời
+	defaultInvitationPath = "/accept-invitation"
+)
+
+// InviteInput là thông tin tạo lời mời
+type InviteInput struct {
+	Email         string
+	Role          string
+	ExpiresInDays int // Bỏ trống = 7 ngày, tối đa 30
+}
+
+type InvitationService interface {
+	Invite(ctx context.Context, inviterID uint, input InviteInput) (*models.Invitation, error)
+	ListPending(ctx context.Context) ([]models.Invitation, error)
+	Resend(ctx context.Context, id uint) (*models.Invitation, error)
+	Revoke(ctx context.Context, id uint) error
+	Accept(ctx context.Context, token, password, fullName string) error
+}
+
+type invitationService struct {
+	repo     repositories.InvitationRepository
+	userRepo repositories.UserRepository
+	roleRepo repositories.RoleRepository
+	orgRepo  repositories.OrganizationRepository
+	hasher   hasher.PasswordHasher
+	policy   PasswordPolicy
+	keys     *jwtkeys.KeySet
+	mailer   mailer.Mailer
+}
+
+func NewInvitationService(
+	repo repositories.InvitationRepository,
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	orgRepo repositories.OrganizationRepository,
+	passwordHasher hasher.PasswordHasher,
+	policy PasswordPolicy,
+	keys *jwtkeys.KeySet,
+	mail mailer.Mailer,
+) InvitationService {
+	return &invitationService{
+		repo:     repo,
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+		orgRepo:  orgRepo,
+		hasher:   passwordHasher,
+		policy:   policy,
+		keys:     keys,
+		mailer:   mail,
+	}
+}
+
+// Invite tạo lời mời và gửi link qua email.
+// Admin đang làm việc trong một tổ chức thì người được mời cũng trở thành thành viên của tổ chức đó
+func (s *invitationService) Invite(ctx context.Context, inviterID uint, input InviteInput) (*models.Invitation, error) {
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = defaultInvitationDays
+	}
+	if input.ExpiresInDays < 1 || input.ExpiresInDays > maxInvitationDays {
+		return nil, custom_error.ErrInvalidInvitationExpiry
+	}
+
+	if _, err := s.userRepo.FindByEmail(ctx, input.Email); err == nil {
+		return nil, custom_error.ErrEmailExists
+	}
+	if _, err := s.repo.FindPendingByEmail(ctx, input.Email); err == nil {
+		return nil, custom_error.ErrInvitationExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	// Giống AdminUpdateUser: không được mời người khác vào role có quyền vượt quá quyền của mình
+	if err := s.checkAssignableRole(ctx, inviterID, input.Role); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation := &models.Invitation{
+		Email:     input.Email,
+		Role:      input.Role,
+		TokenID:   uuid.NewString(),
+		InvitedBy: inviterID,
+		ExpiresAt: now.Add(time.Duration(input.ExpiresInDays) * 24 * time.Hour),
+		SentAt:    now,
+	}
+	if organizationID, ok := repositories.TenantFromContext(ctx); ok {
+		invitation.OrganizationID = &organizationID
+		invitation.OrgRole = models.OrgRoleMember
+	}
+
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	if err := s.sendInvitationEmail(invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *invitationService) checkAssignableRole(ctx context.Context, inviterID uint, roleName string) error {
+	role, err := s.roleRepo.FindByName(ctx, roleName)
+	if err != nil {
+		return custom_error.ErrInvalidRole
+	}
+
+	inviter, err := s.userRepo.FindByID(ctx, inviterID)
+	if err != nil {
+		return custom_error.ErrUnauthorized
+	}
+	inviterRole, err := s.roleRepo.FindByName(ctx, inviter.Role)
+	if err != nil || !coversPermissions(inviterRole, role) {
+		return custom_error.ErrForbidden
+	}
+	return nil
+}
+
+func (s *invitationService) ListPending(ctx context.Context) ([]models.Invitation, error) {
+	invitations, err := s.repo.ListPending(ctx)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return invitations, nil
+}
+
+// Resend gửi lại email với link mới (link cũ hết hiệu lực) và gia hạn lời mời thêm đúng thời hạn ban đầu
+func (s *invitationService) Resend(ctx context.Context, id uint) (*models.Invitation, error) {
+	invitation, err := s.repo.FindByID(ctx, id)
+	if err != nil || !invitation.IsPending() || invitation.IsMembershipOnly() {
+		return nil, custom_error.ErrInvitationNotFound
+	}
+
+	if time.Since(invitation.SentAt) < invitationResend {
+		return nil, custom_error.ErrTooManyRequests
+	}
+
+	now := time.Now()
+	validFor := invitation.ExpiresAt.Sub(invitation.SentAt)
+	invitation.TokenID = uuid.NewString()
+	invitation.SentAt = now
+	invitation.ExpiresAt = now.Add(validFor)
+
+	if err := s.repo.Update(ctx, invitation); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	if err := s.sendInvitationEmail(invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *invitationService) Revoke(ctx context.Context, id uint) error {
+	invitation, err := s.repo.FindByID(ctx, id)
+	if err != nil || !invitation.IsPending() || invitation.IsMembershipOnly() {
+		return custom_error.ErrInvitationNotFound
+	}
+
+	now := time.Now()
+	invitation.RevokedAt = &now
+	if err := s.repo.Update(ctx, invitation); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// Accept tạo tài khoản từ lời mời với mật khẩu người dùng tự đặt.
+// Bấm được link trong email nghĩa là đã sở hữu email, nên tài khoản được xác thực email luôn
+func (s *invitationService) Accept(ctx context.Context, token, password, fullName string) error {
+	claims, err := s.keys.Parse(token)
+	if err != nil || claims["token_type"] != "invitation" {
+		return custom_error.ErrInvalidInvitation
+	}
+
+	invitationIDFloat, okID := claims["invitation_id"].(float64)
+	tokenID, okJTI := claims["jti"].(string)
+	if !okID || !okJTI {
+		return custom_error.ErrInvalidInvitation
+	}
+
+	invitation, err := s.repo.FindByID(ctx, uint(invitationIDFloat))
+	if err != nil || invitation.TokenID != tokenID || !invitation.IsUsable() || invitation.IsMembershipOnly() {
+		return custom_error.ErrInvalidInvitation
+	}
+
+	if _, err := s.userRepo.FindByEmail(ctx, invitation.Email); err == nil {
+		return custom_error.ErrEmailExists
+	}
+
+	if err := s.policy.Validate(ctx, "password", password, &models.User{Email: invitation.Email}); err != nil {
+		return err
+	}
+
+	// Role có thể đã bị xoá sau khi mời, khi đó dùng role mặc định
+	role := invitation.Role
+	if _, err := s.roleRepo.FindByName(ctx, role); err != nil {
+		role = models.RoleUser
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Email:           invitation.Email,
+		EmailVerifiedAt: &now,
+		Password:        hashedPassword,
+		FullName:        fullName,
+		Role:            role,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		// Unique index trên email: một request chấp nhận khác vừa tạo tài khoản trước
+		return custom_error.ErrEmailExists
+	}
+	s.policy.Remember(ctx, user.ID, hashedPassword)
+
+	invitation.AcceptedAt = &now
+	if err := s.repo.Update(ctx, invitation); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	if invitation.OrganizationID != nil {
+		membership := &models.Membership{OrganizationID: *invitation.OrganizationID, UserID: user.ID, Role: invitation.OrgRole}
+		if err := s.orgRepo.AddMember(ctx, membership); err != nil {
+			logger.Error("Không thể thêm thành viên từ lời mời",
+				zap.Uint("invitation_id", invitation.ID),
+				zap.Uint("organization_id", *invitation.OrganizationID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// sendInvitationEmail ký link mời và gửi ngầm qua Worker Pool
+func (s *invitationService) sendInvitationEmail(invitation *models.Invitation) error {
+	token, err := s.keys.Sign(jwt.MapClaims{
+		"token_type":    "invitation",
+		"invitation_id": invitation.ID,
+		"jti":           invitation.TokenID,
+		"exp":           invitation.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		logger.Error("Lỗi ký link mời", zap.Error(err))
+		return custom_error.ErrInternalServer
+	}
+
+	baseURL := config.AppConfig.Auth.InvitationURL
+	if baseURL == "" {
+		baseURL = config.AppConfig.Server.Domain + defaultInvitationPath
+	}
+	link := baseURL + "?token=" + url.QueryEscape(token)
+	email := invitation.Email
+	expiresAt := invitation.ExpiresAt.Format("02/01/2006 15:04")
+
+	utils.RunInBackground(func() {
+		subject := "📨 You've been invited to join [YourApp]"
+		body, err := templates.Render("invitation.html", map[string]interface{}{
+			"Email":     email,
+			"Link":      link,
+			"ExpiresAt": expiresAt,
+		})
+
+		if err != nil {
+			logger.Error("Lỗi render template lời mời", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(email, subject, body); err != nil {
+			logger.Error("Lỗi gửi email lời mời", zap.Error(err))
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// loginGuard chống dò mật khẩu nhắm vào một tài khoản cụ thể (kể cả khi kẻ tấn công dùng nhiều IP):
+//   - Theo cặp IP + tài khoản: sau vài lần sai, thời gian chờ tăng gấp đôi mỗi lần (exponential backoff)
+//   - Theo tài khoản: sai quá nhiều lần thì khoá tạm thời và gửi email báo chủ tài khoản
+type loginGuard struct {
+	repo   repositories.LoginThrottleRepository
+	mailer mailer.Mailer
+}
+
+func accountKey(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+func pairKey(userID uint, ip string) string {
+	return fmt.Sprintf("user:%d|ip:%s", userID, ip)
+}
+
+// lockoutPolicy đọc cấu hình, điền giá trị mặc định an toàn nếu bỏ trống
+func lockoutPolicy() (freeAttempts, maxFailures int, lockout, maxDelay, window time.Duration) {
+	cfg := config.AppConfig.Auth.Lockout
+	freeAttempts, maxFailures = cfg.FreeAttempts, cfg.MaxFailures
+	lockout = time.Duration(cfg.LockoutMinutes) * time.Minute
+	maxDelay = time.Duration(cfg.MaxDelaySeconds) * time.Second
+	window = time.Duration(cfg.FailureWindowMinutes) * time.Minute
+
+	if freeAttempts <= 0 {
+		freeAttempts = 3
+	}
+	if maxFailures <= 0 {
+		maxFailures = 10
+	}
+	if lockout <= 0 {
+		lockout = 15 * time.Minute
+	}
+	if maxDelay <= 0 {
+		maxDelay = 15 * time.Minute
+	}
+	if window <= 0 {
+		window = time.Hour
+	}
+	return
+}
+
+// check từ chối lượt đăng nhập nếu tài khoản đang bị khoá hoặc cặp IP + tài khoản chưa hết thời gian chờ
+func (g *loginGuard) check(ctx context.Context, userID uint, ip string) error {
+	account, err := g.repo.Find(ctx, accountKey(userID))
+	if err == nil && account.IsLocked() {
+		return custom_error.ErrAccountLocked
+	}
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return custom_error.ErrInternalServer
+	}
+
+	pair, err := g.repo.Find(ctx, pairKey(userID, ip))
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	if wait := time.Until(pair.LastFailedAt.Add(backoffDelay(pair.Failures))); wait > 0 {
+		return custom_error.New(http.StatusTooManyRequests, "ERR_LOGIN_THROTTLED",
+			fmt.Sprintf("Đăng nhập sai nhiều lần. Vui lòng thử lại sau %d giây", int(wait.Seconds())+1))
+	}
+	return nil
+}
+
+// backoffDelay: miễn phí vài lần đầu, sau đó 1s, 2s, 4s, 8s... tối đa maxDelay
+func backoffDelay(failures int) time.Duration {
+	freeAttempts, _, _, maxDelay, _ := lockoutPolicy()
+	exceeded := failures - freeAttempts
+	if exceeded <= 0 {
+		return 0
+	}
+	if exceeded > 20 {
+		return maxDelay // Tránh tràn số khi dịch bit
+	}
+
+	delay := time.Second << (exceeded - 1)
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// recordFailure ghi nhận một lần sai (mật khẩu hoặc mã 2FA), khoá tài khoản khi vượt ngưỡng
+func (g *loginGuard) recordFailure(ctx context.Context, user *models.User, ip string) {
+	_, maxFailures, lockout, _, window := lockoutPolicy()
+
+	if _, err := g.repo.RecordFailure(ctx, pairKey(user.ID, ip), user.ID, window); err != nil {
+		logger.Error("Không thể ghi nhận đăng nhập sai", zap.Error(err))
+	}
+
+	account, err := g.repo.RecordFailure(ctx, accountKey(user.ID), user.ID, window)
+	if err != nil {
+		logger.Error("Không thể ghi nhận đăng nhập sai", zap.Error(err))
+		return
+	}
+	if account.Failures < maxFailures {
+		return
+	}
+
+	if err := g.repo.Lock(ctx, accountKey(user.ID), time.Now().Add(lockout)); err != nil {
+		logger.Error("Không thể khoá tài khoản", zap.Error(err))
+		return
+	}
+
+	logger.Warn("Tài khoản bị khoá tạm thời do đăng nhập sai nhiều lần",
+		zap.String("event", "account_locked"),
+		zap.Uint("user_id", user.ID),
+		zap.String("ip", ip),
+	)
+	g.notifyLocked(user.Email, ip, lockout)
+}
+
+// reset xoá bộ đếm sau khi đăng nhập thành công
+func (g *loginGuard) reset(ctx context.Context, userID uint, ip string) {
+	if err := g.repo.Reset(ctx, accountKey(userID), pairKey(userID, ip)); err != nil {
+		logger.Error("Không thể đặt lại bộ đếm đăng nhập", zap.Error(err))
+	}
+}
+
+func (g *loginGuard) notifyLocked(email, ip string, lockout time.Duration) {
+	now := time.Now().Format(time.RFC1123)
+	utils.RunInBackground(func() {
+		subject := "⚠️ Your account has been temporarily locked"
+		body, err := templates.Render("account_locked.html", map[string]interface{}{
+			"Email":   email,
+			"IP":      ip,
+			"Time":    now,
+			"Minutes": int(lockout.Minutes()),
+		})
+
+		if err != nil {
+			logger.Error("Lỗi render template account locked", zap.Error(err))
+			return
+		}
+		if err := g.mailer.SendMail(email, subject, body); err != nil {
+			logger.Error("Lỗi gửi email cảnh báo khoá tài khoản", zap.Error(err))
+		}
+	})
+}
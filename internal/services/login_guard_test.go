@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/custom_error"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	// Cấu hình mặc định: miễn phí 3 lần, tối đa 15 phút
+	cases := map[int]time.Duration{
+		0:   0,
+		3:   0,
+		4:   time.Second,
+		5:   2 * time.Second,
+		6:   4 * time.Second,
+		13:  512 * time.Second,
+		14:  15 * time.Minute, // 1024s vượt maxDelay
+		100: 15 * time.Minute,
+	}
+	for failures, want := range cases {
+		if got := backoffDelay(failures); got != want {
+			t.Errorf("backoffDelay(%d) = %v, muốn %v", failures, got, want)
+		}
+	}
+}
+
+func TestLoginGuardBackoffPerIP(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThrottleRepo()
+	guard := &loginGuard{repo: repo}
+	user := &models.User{ID: 7, Email: "user@example.com"}
+
+	for i := 0; i < 3; i++ {
+		guard.recordFailure(ctx, user, "1.1.1.1")
+		if err := guard.check(ctx, user.ID, "1.1.1.1"); err != nil {
+			t.Fatalf("lần sai thứ %d vẫn trong số lần miễn phí, nhận %v", i+1, err)
+		}
+	}
+
+	guard.recordFailure(ctx, user, "1.1.1.1")
+	var appErr *custom_error.AppError
+	if err := guard.check(ctx, user.ID, "1.1.1.1"); !errors.As(err, &appErr) || appErr.HTTPCode != http.StatusTooManyRequests {
+		t.Fatalf("lần sai thứ 4 phải bị chờ, nhận %v", err)
+	}
+
+	// Thời gian chờ tính theo cặp IP + tài khoản: IP khác chưa sai lần nào không bị chặn
+	if err := guard.check(ctx, user.ID, "2.2.2.2"); err != nil {
+		t.Fatalf("IP khác bị chặn: %v", err)
+	}
+
+	// Hết thời gian chờ thì được thử lại
+	repo.entries[pairKey(user.ID, "1.1.1.1")].LastFailedAt = time.Now().Add(-2 * time.Second)
+	if err := guard.check(ctx, user.ID, "1.1.1.1"); err != nil {
+		t.Fatalf("đã hết thời gian chờ, nhận %v", err)
+	}
+
+	guard.reset(ctx, user.ID, "1.1.1.1")
+	if _, ok := repo.entries[pairKey(user.ID, "1.1.1.1")]; ok {
+		t.Fatal("đăng nhập thành công phải xoá bộ đếm của cặp IP + tài khoản")
+	}
+}
+
+func TestLoginGuardLocksAccountAcrossIPs(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThrottleRepo()
+	guard := &loginGuard{repo: repo}
+	user := &models.User{ID: 7, Email: "user@example.com"}
+
+	// Kẻ tấn công đổi IP sau mỗi lần thử: backoff theo IP không có tác dụng, khoá theo tài khoản thì có
+	ips := []string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4", "5.5.5.5"}
+	for i := 0; i < 9; i++ {
+		guard.recordFailure(ctx, user, ips[i%len(ips)])
+	}
+	if err := guard.check(ctx, user.ID, "9.9.9.9"); err != nil {
+		t.Fatalf("chưa tới ngưỡng khoá, nhận %v", err)
+	}
+
+	guard.recordFailure(ctx, user, ips[0])
+	if err := guard.check(ctx, user.ID, "9.9.9.9"); !errors.Is(err, custom_error.ErrAccountLocked) {
+		t.Fatalf("lần sai thứ 10 phải khoá tài khoản với mọi IP, nhận %v", err)
+	}
+}
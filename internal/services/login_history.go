@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"go.uber.org/zap"
+)
+
+type LoginHistoryService interface {
+	ListByUser(ctx context.Context, userID uint, pagination utils.Pagination) ([]models.LoginAttempt, int64, int, error)
+}
+
+type loginHistoryService struct {
+	repo     repositories.LoginHistoryRepository
+	userRepo repositories.UserRepository
+}
+
+func NewLoginHistoryService(repo repositories.LoginHistoryRepository, userRepo repositories.UserRepository) LoginHistoryService {
+	return &loginHistoryService{repo: repo, userRepo: userRepo}
+}
+
+// ListByUser trả về lịch sử đăng nhập mới nhất trước. Admin trong một tổ chức chỉ xem được thành viên của tổ chức đó
+func (s *loginHistoryService) ListByUser(ctx context.Context, userID uint, pagination utils.Pagination) ([]models.LoginAttempt, int64, int, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, 0, 0, custom_error.ErrUserNotFound
+	}
+
+	attempts, total, err := s.repo.ListByUser(ctx, userID, pagination)
+	if err != nil {
+		return nil, 0, 0, custom_error.ErrInternalServer
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(pagination.Limit)))
+	return attempts, total, totalPages, nil
+}
+
+// loginHistory ghi lại từng lượt đăng nhập và gửi email cảnh báo khi tài khoản được đăng nhập từ thiết bị lạ
+type loginHistory struct {
+	repo   repositories.LoginHistoryRepository
+	mailer mailer.Mailer
+}
+
+// record ghi nhận lượt đăng nhập (thành công khi err == nil) qua Worker Pool để không làm chậm request.
+// user = nil khi email không thuộc tài khoản nào
+func (h *loginHistory) record(user *models.User, email string, client ClientInfo, method string, err error) {
+	entry := &models.LoginAttempt{
+		Email:     email,
+		Method:    method,
+		Success:   err == nil,
+		IP:        client.IP,
+		IPRange:   ipRange(client.IP),
+		UserAgent: client.UserAgent,
+	}
+	if user != nil {
+		entry.UserID = &user.ID
+		entry.Email = user.Email
+	}
+	if err != nil {
+		var appErr *custom_error.AppError
+		if errors.As(err, &appErr) {
+			entry.FailureCode = appErr.Code
+		} else {
+			entry.FailureCode = custom_error.ErrInternalServer.Code
+		}
+	}
+
+	utils.RunInBackground(func() {
+		// Context của request có thể đã bị huỷ khi tác vụ nền chạy
+		ctx := context.Background()
+		if entry.Success {
+			entry.NewDevice = h.isNewDevice(ctx, *entry.UserID, entry.UserAgent, entry.IPRange)
+		}
+
+		if err := h.repo.Create(ctx, entry); err != nil {
+			logger.Error("Không thể ghi lịch sử đăng nhập", zap.String("email", entry.Email), zap.Error(err))
+		}
+
+		if entry.NewDevice {
+			h.notifyNewDevice(entry)
+		}
+	})
+}
+
+// isNewDevice: thiết bị (user agent) hoặc dải IP chưa từng đăng nhập thành công.
+// Lần đăng nhập đầu tiên của tài khoản không tính là thiết bị lạ
+func (h *loginHistory) isNewDevice(ctx context.Context, userID uint, userAgent, ipRange string) bool {
+	hasHistory, err := h.repo.HasSuccess(ctx, userID, "", "")
+	if err != nil || !hasHistory {
+		return false
+	}
+
+	knownAgent, err := h.repo.HasSuccess(ctx, userID, userAgent, "")
+	if err != nil {
+		return false
+	}
+	knownRange, err := h.repo.HasSuccess(ctx, userID, "", ipRange)
+	if err != nil {
+		return false
+	}
+	return !knownAgent || !knownRange
+}
+
+func (h *loginHistory) notifyNewDevice(entry *models.LoginAttempt) {
+	subject := "🔐 New sign-in to your account"
+	body, err := templates.Render("new_sign_in.html", map[string]interface{}{
+		"Email":  entry.Email,
+		"IP":     entry.IP,
+		"Device": entry.UserAgent,
+		"Method": entry.Method,
+		"Time":   entry.CreatedAt.Format(time.RFC1123),
+	})
+	if err != nil {
+		logger.Error("Lỗi render template đăng nhập mới", zap.Error(err))
+		return
+	}
+	if err := h.mailer.SendMail(entry.Email, subject, body); err != nil {
+		logger.Error("Lỗi gửi email cảnh báo đăng nhập mới", zap.Error(err))
+	}
+}
+
+// ipRange gom IP về dải mạng: /24 với IPv4, /48 với IPv6 (cùng nhà mạng/văn phòng thường chung dải)
+func ipRange(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"go-core-api/pkg/custom_error"
+)
+
+// TokenLookupRequest là tham số của /oauth/introspect và /oauth/revoke.
+// token_type_hint được bỏ qua vì loại token đã nằm trong claim token_type
+type TokenLookupRequest struct {
+	Token        string
+	ClientID     string
+	ClientSecret string
+}
+
+// IntrospectionResponse là phản hồi của /oauth/introspect (RFC 7662 mục 2.2).
+// Token không còn hiệu lực chỉ trả về {"active": false}
+type IntrospectionResponse struct {
+	Active   bool   `json:"active"`
+	Sub      string `json:"sub,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Exp      int64  `json:"exp,omitempty"`
+	Iat      int64  `json:"iat,omitempty"`
+	Iss      string `json:"iss,omitempty"`
+}
+
+// ============================================================================
+// /oauth/introspect và /oauth/revoke
+// ============================================================================
+
+// Introspect cho gateway và service khác hỏi token (access/refresh) còn dùng được hay không.
+// Ngoài chữ ký và hạn dùng còn chạy TokenValidator giống RequireAuth (phiên, quyền, đình chỉ, quyền giả lập, membership),
+// nên token bị vô hiệu sau khi cấp (đổi mật khẩu, đăng xuất...) trả về active = false
+func (s *oauthServerService) Introspect(ctx context.Context, req TokenLookupRequest) (*IntrospectionResponse, error) {
+	client, err := s.AuthenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	// Client công khai không giữ được bí mật, không được dò trạng thái token
+	if client.Public {
+		return nil, oauthError(http.StatusBadRequest, "unauthorized_client", "Client công khai không được dùng introspection")
+	}
+	if req.Token == "" {
+		return nil, oauthError(http.StatusBadRequest, "invalid_request", "Thiếu token")
+	}
+
+	inactive := &IntrospectionResponse{Active: false}
+	claims, err := s.keys.Parse(req.Token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	resp := &IntrospectionResponse{Active: true, Iss: oidcIssuer()}
+	resp.Scope, _ = claims["scope"].(string)
+	resp.ClientID, _ = claims["client_id"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		resp.Exp = int64(exp)
+	}
+	if iat, ok := claims["iat"].(float64); ok {
+		resp.Iat = int64(iat)
+	}
+
+	switch claims["token_type"] {
+	case "access", "refresh":
+		// Cùng bộ kiểm tra với RequireAuth, token bị RequireAuth từ chối thì trả về active = false
+		userClaims, err := ParseUserTokenClaims(claims)
+		if err != nil {
+			return inactive, nil
+		}
+		validated, err := s.validator.Validate(ctx, userClaims)
+		if err != nil {
+			return inactive, nil
+		}
+		resp.Sub = strconv.FormatUint(uint64(validated.User.ID), 10)
+		resp.Role = validated.User.Role
+	case "client_access":
+		tokenClient, err := s.clientRepo.FindByClientID(ctx, resp.ClientID)
+		if err != nil || !tokenClient.IsActive() {
+			return inactive, nil
+		}
+		resp.Sub = tokenClient.ClientID
+	default:
+		// Challenge 2FA, magic link... không phải token truy cập
+		return inactive, nil
+	}
+	return resp, nil
+}
+
+// Revoke thu hồi token theo RFC 7009: thu hồi phiên (cả Access lẫn Refresh Token) giống RevokeToken khi đăng xuất.
+// Client chỉ thu hồi được token cấp cho chính nó. Token không hợp lệ hoặc đã hết hạn vẫn trả về thành công
+func (s *oauthServerService) Revoke(ctx context.Context, req TokenLookupRequest) error {
+	client, err := s.AuthenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return oauthError(http.StatusBadRequest, "invalid_request", "Thiếu token")
+	}
+
+	claims, err := s.keys.Parse(req.Token)
+	if err != nil {
+		return nil
+	}
+
+	tokenClientID, _ := claims["client_id"].(string)
+	if tokenClientID != client.ClientID {
+		return oauthError(http.StatusBadRequest, "unauthorized_client", "Token không được cấp cho ứng dụng này")
+	}
+
+	switch claims["token_type"] {
+	case "access", "refresh":
+	case "client_access":
+		// Token client_credentials không gắn với phiên nào, chỉ hết hạn theo exp
+		return oauthError(http.StatusBadRequest, "unsupported_token_type", "Không hỗ trợ thu hồi token client_credentials")
+	default:
+		return nil
+	}
+
+	userIDFloat, okID := claims["user_id"].(float64)
+	sessionIDFloat, okSession := claims["session_id"].(float64)
+	if !okID || !okSession {
+		return nil
+	}
+
+	// Phiên không còn (đã thu hồi trước đó) cũng coi như thành công
+	if err := revokeSession(ctx, s.sessionRepo, uint(userIDFloat), uint(sessionIDFloat)); err != nil && err != custom_error.ErrSessionNotFound {
+		return err
+	}
+	return nil
+}
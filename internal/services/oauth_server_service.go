@@ -0,0 +1,751 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/jwtkeys"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/oauth"
+	"go-core-api/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	authorizationCodeTTL = 5 * time.Minute
+	clientSecretPrefix   = "ocs_"
+)
+
+// OAuthError là lỗi theo chuẩn OAuth2 (RFC 6749 mục 4.1.2.1 và 5.2).
+// Client OAuth đọc đúng định dạng {"error": "..."} nên lỗi này không đi qua response.Error
+type OAuthError struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	// Redirectable = true khi client và redirect_uri đã hợp lệ, lỗi được trả về qua redirect_uri
+	Redirectable bool `json:"-"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// RedirectURL gắn lỗi vào redirect_uri của client
+func (e *OAuthError) RedirectURL(redirectURI, state string) string {
+	params := url.Values{"error": {e.Code}, "iss": {oidcIssuer()}}
+	if e.Description != "" {
+		params.Set("error_description", e.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+func oauthError(status int, code, description string) *OAuthError {
+	return &OAuthError{Status: status, Code: code, Description: description}
+}
+
+func redirectableError(code, description string) *OAuthError {
+	return &OAuthError{Status: http.StatusBadRequest, Code: code, Description: description, Redirectable: true}
+}
+
+var (
+	errInvalidClient = oauthError(http.StatusUnauthorized, "invalid_client", "Xác thực client thất bại")
+	errInvalidGrant  = oauthError(http.StatusBadRequest, "invalid_grant", "Code hoặc refresh token không hợp lệ, đã hết hạn hoặc đã được dùng")
+)
+
+// AuthorizeRequest là tham số của /oauth/authorize
+type AuthorizeRequest struct {
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizeContext là thông tin đã kiểm tra để hiển thị màn hình đồng ý cấp quyền
+type AuthorizeContext struct {
+	Client *models.OAuthClient
+	Scopes []string
+}
+
+// TokenRequest là tham số của /oauth/token
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthTokenResponse là phản hồi thành công của /oauth/token (RFC 6749 mục 5.1)
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// RegisterClientInput là thông tin đăng ký một ứng dụng (client) mới
+type RegisterClientInput struct {
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	Public       bool
+}
+
+// RegisteredClient trả về client vừa tạo, ClientSecret chỉ hiển thị đúng một lần
+type RegisteredClient struct {
+	Client       *models.OAuthClient `json:"client"`
+	ClientSecret string              `json:"client_secret,omitempty"`
+}
+
+// OAuthServerService biến hệ thống thành một OpenID Provider tối giản cho các ứng dụng nội bộ
+type OAuthServerService interface {
+	RegisterClient(ctx context.Context, createdBy uint, input RegisterClientInput) (*RegisteredClient, error)
+	ListClients(ctx context.Context) ([]models.OAuthClient, error)
+	RevokeClient(ctx context.Context, id uint) error
+	ValidateAuthorize(ctx context.Context, req AuthorizeRequest) (*AuthorizeContext, error)
+	SessionUser(ctx context.Context, accessToken string) (*models.User, time.Time, bool)
+	Authorize(ctx context.Context, req AuthorizeRequest, user *models.User, authTime time.Time) (string, error)
+	Token(ctx context.Context, req TokenRequest, client ClientInfo) (*OAuthTokenResponse, error)
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*models.OAuthClient, error)
+	Introspect(ctx context.Context, req TokenLookupRequest) (*IntrospectionResponse, error)
+	Revoke(ctx context.Context, req TokenLookupRequest) error
+	UserInfo(ctx context.Context, userID uint, scopes []string) (map[string]interface{}, error)
+	Discovery() map[string]interface{}
+}
+
+type oauthServerService struct {
+	repo        repositories.UserRepository
+	sessionRepo repositories.SessionRepository
+	clientRepo  repositories.OAuthClientRepository
+	codeRepo    repositories.OAuthCodeRepository
+	roleRepo    repositories.RoleRepository
+	validator   TokenValidator
+	keys        *jwtkeys.KeySet
+}
+
+func NewOAuthServerService(
+	repo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	clientRepo repositories.OAuthClientRepository,
+	codeRepo repositories.OAuthCodeRepository,
+	roleRepo repositories.RoleRepository,
+	orgRepo repositories.OrganizationRepository,
+	keys *jwtkeys.KeySet,
+) OAuthServerService {
+	return &oauthServerService{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		clientRepo:  clientRepo,
+		codeRepo:    codeRepo,
+		roleRepo:    roleRepo,
+		validator:   NewTokenValidator(repo, sessionRepo, roleRepo, orgRepo),
+		keys:        keys,
+	}
+}
+
+// oidcIssuer là định danh của authorization server, trùng với domain công khai của hệ thống
+func oidcIssuer() string {
+	return strings.TrimRight(config.AppConfig.Server.Domain, "/")
+}
+
+// appendQuery nối tham số vào URL, giữ nguyên query có sẵn của redirect_uri
+func appendQuery(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode()
+}
+
+func isSupportedScope(scope string) bool {
+	for _, list := range [][]string{models.OIDCScopes, models.AllScopes} {
+		for _, s := range list {
+			if s == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// Quản lý client
+// ============================================================================
+
+func (s *oauthServerService) RegisterClient(ctx context.Context, createdBy uint, input RegisterClientInput) (*RegisteredClient, error) {
+	if len(input.GrantTypes) == 0 {
+		input.GrantTypes = []string{models.GrantAuthorizationCode, models.GrantRefreshToken}
+	}
+	if len(input.Scopes) == 0 {
+		input.Scopes = []string{models.ScopeOpenID, models.ScopeProfile, models.ScopeEmail}
+	}
+
+	var details []custom_error.FieldError
+	for _, grant := range input.GrantTypes {
+		switch grant {
+		case models.GrantAuthorizationCode, models.GrantRefreshToken:
+		case models.GrantClientCredentials:
+			if input.Public {
+				details = append(details, custom_error.FieldError{Field: "grant_types", Code: "public_client_credentials", Message: "Client công khai không được dùng client_credentials"})
+			}
+		default:
+			details = append(details, custom_error.FieldError{Field: "grant_types", Code: "unsupported", Message: "Grant type không được hỗ trợ: " + grant})
+		}
+	}
+	for _, scope := range input.Scopes {
+		if !isSupportedScope(scope) {
+			details = append(details, custom_error.FieldError{Field: "scopes", Code: "unsupported", Message: "Scope không được hỗ trợ: " + scope})
+		}
+	}
+	if hasScope(input.GrantTypes, models.GrantAuthorizationCode) && len(input.RedirectURIs) == 0 {
+		details = append(details, custom_error.FieldError{Field: "redirect_uris", Code: "required", Message: "Cần ít nhất một redirect_uri cho authorization_code"})
+	}
+	for _, redirectURI := range input.RedirectURIs {
+		if !isValidRedirectURI(redirectURI) {
+			details = append(details, custom_error.FieldError{Field: "redirect_uris", Code: "invalid", Message: "redirect_uri phải là URL https (hoặc http://localhost) và không chứa fragment: " + redirectURI})
+		}
+	}
+	if len(details) > 0 {
+		return nil, custom_error.ErrInvalidOAuthClient.WithDetails(details...)
+	}
+
+	clientID, err := utils.GenerateRandomString(24, alphanumericAlphabet)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Name:         input.Name,
+		RedirectURIs: input.RedirectURIs,
+		Scopes:       input.Scopes,
+		GrantTypes:   input.GrantTypes,
+		Public:       input.Public,
+		CreatedBy:    createdBy,
+	}
+
+	var secret string
+	if !input.Public {
+		raw, err := utils.GenerateRandomString(40, alphanumericAlphabet)
+		if err != nil {
+			return nil, custom_error.ErrInternalServer
+		}
+		secret = clientSecretPrefix + raw
+		client.SecretHash = utils.HashToken(secret)
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return &RegisteredClient{Client: client, ClientSecret: secret}, nil
+}
+
+// isValidRedirectURI chỉ chấp nhận https, hoặc http cho localhost khi phát triển
+func isValidRedirectURI(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+		return false
+	}
+	if u.Scheme == "https" {
+		return true
+	}
+	host := u.Hostname()
+	return u.Scheme == "http" && (host == "localhost" || host == "127.0.0.1" || host == "::1")
+}
+
+func (s *oauthServerService) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	clients, err := s.clientRepo.ListActive(ctx)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return clients, nil
+}
+
+// RevokeClient thu hồi client và mọi phiên (token) đã cấp cho nó
+func (s *oauthServerService) RevokeClient(ctx context.Context, id uint) error {
+	client, err := s.clientRepo.FindByID(ctx, id)
+	if err != nil || !client.IsActive() {
+		return custom_error.ErrOAuthClientNotFound
+	}
+
+	if _, err := s.clientRepo.Revoke(ctx, client.ID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if err := s.sessionRepo.RevokeAllByClient(ctx, client.ClientID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// AuthenticateClient xác thực client theo client_id + client_secret.
+// Client công khai không có secret, thay vào đó mọi authorization code của nó đều phải kèm PKCE
+func (s *oauthServerService) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, errInvalidClient
+	}
+
+	client, err := s.clientRepo.FindByClientID(ctx, clientID)
+	if err != nil || !client.IsActive() {
+		return nil, errInvalidClient
+	}
+
+	if client.Public {
+		if clientSecret != "" {
+			return nil, errInvalidClient
+		}
+		return client, nil
+	}
+
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(utils.HashToken(clientSecret))) != 1 {
+		return nil, errInvalidClient
+	}
+	return client, nil
+}
+
+// ============================================================================
+// /oauth/authorize
+// ============================================================================
+
+// ValidateAuthorize kiểm tra yêu cầu cấp quyền trước khi hiển thị màn hình đồng ý.
+// Lỗi client_id/redirect_uri không được redirect (tránh open redirect), các lỗi còn lại trả về qua redirect_uri
+func (s *oauthServerService) ValidateAuthorize(ctx context.Context, req AuthorizeRequest) (*AuthorizeContext, error) {
+	client, err := s.clientRepo.FindByClientID(ctx, req.ClientID)
+	if err != nil || !client.IsActive() {
+		return nil, oauthError(http.StatusBadRequest, "invalid_client", "Ứng dụng không tồn tại hoặc đã bị thu hồi")
+	}
+	if req.RedirectURI == "" || !client.AllowsRedirect(req.RedirectURI) {
+		return nil, oauthError(http.StatusBadRequest, "invalid_request", "redirect_uri không khớp với đăng ký của ứng dụng")
+	}
+
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return nil, redirectableError("unauthorized_client", "Ứng dụng không được phép dùng authorization_code")
+	}
+	if req.ResponseType != "code" {
+		return nil, redirectableError("unsupported_response_type", "Chỉ hỗ trợ response_type=code")
+	}
+	// Bắt buộc PKCE S256 với mọi client (theo khuyến nghị OAuth 2.1)
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return nil, redirectableError("invalid_request", "Bắt buộc PKCE với code_challenge_method=S256")
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		return nil, redirectableError("invalid_scope", "Thiếu scope")
+	}
+	for _, scope := range scopes {
+		if !isSupportedScope(scope) || !client.AllowsScope(scope) {
+			return nil, redirectableError("invalid_scope", "Scope không được phép: "+scope)
+		}
+	}
+	if hasScope(scopes, models.ScopeOfflineAccess) && !client.AllowsGrant(models.GrantRefreshToken) {
+		return nil, redirectableError("invalid_scope", "Ứng dụng không được phép dùng refresh_token")
+	}
+
+	return &AuthorizeContext{Client: client, Scopes: scopes}, nil
+}
+
+// SessionUser trả về user của phiên đăng nhập trên trình duyệt (cookie access_token) để màn hình đồng ý
+// không hỏi lại mật khẩu. Chỉ chấp nhận token của phiên đăng nhập trực tiếp, không phải token giả lập,
+// và user phải chứng minh thông tin đăng nhập (auth_time) trong vòng auth.reauth_max_age_minutes
+func (s *oauthServerService) SessionUser(ctx context.Context, accessToken string) (*models.User, time.Time, bool) {
+	claims, err := s.keys.Parse(accessToken)
+	if err != nil || claims["token_type"] != "access" {
+		return nil, time.Time{}, false
+	}
+	userClaims, err := ParseUserTokenClaims(claims)
+	if err != nil || userClaims.Impersonating || userClaims.ClientID != "" {
+		return nil, time.Time{}, false
+	}
+
+	value, ok := claims["auth_time"].(float64)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	authTime := time.Unix(int64(value), 0)
+	if time.Since(authTime) > reauthMaxAge() {
+		return nil, time.Time{}, false
+	}
+
+	validated, err := s.validator.Validate(ctx, userClaims)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return validated.User, authTime, true
+}
+
+// Authorize cấp authorization code cho user đã đăng nhập và đồng ý, trả về URL chuyển hướng về client.
+// authTime là thời điểm user nhập thông tin đăng nhập, đi vào claim auth_time của ID Token
+func (s *oauthServerService) Authorize(ctx context.Context, req AuthorizeRequest, user *models.User, authTime time.Time) (string, error) {
+	authorizeCtx, err := s.ValidateAuthorize(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	rawCode, err := utils.GenerateRandomString(48, alphanumericAlphabet)
+	if err != nil {
+		return "", custom_error.ErrInternalServer
+	}
+
+	now := time.Now()
+	code := &models.OAuthAuthorizationCode{
+		CodeHash:      utils.HashToken(rawCode),
+		ClientID:      authorizeCtx.Client.ClientID,
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         strings.Join(authorizeCtx.Scopes, " "),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      authTime,
+		ExpiresAt:     now.Add(authorizationCodeTTL),
+	}
+	if err := s.codeRepo.Create(ctx, code); err != nil {
+		return "", custom_error.ErrInternalServer
+	}
+
+	params := url.Values{"code": {rawCode}, "iss": {oidcIssuer()}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, params), nil
+}
+
+// ============================================================================
+// /oauth/token
+// ============================================================================
+
+func (s *oauthServerService) Token(ctx context.Context, req TokenRequest, clientInfo ClientInfo) (*OAuthTokenResponse, error) {
+	client, err := s.AuthenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case models.GrantAuthorizationCode, models.GrantRefreshToken, models.GrantClientCredentials:
+		if !client.AllowsGrant(req.GrantType) {
+			return nil, oauthError(http.StatusBadRequest, "unauthorized_client", "Ứng dụng không được phép dùng grant type này")
+		}
+	default:
+		return nil, oauthError(http.StatusBadRequest, "unsupported_grant_type", "Grant type không được hỗ trợ")
+	}
+
+	clientInfo.ClientID = client.ClientID
+	clientInfo.DeviceName = client.Name
+
+	switch req.GrantType {
+	case models.GrantAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req, clientInfo)
+	case models.GrantRefreshToken:
+		return s.exchangeRefreshToken(ctx, client, req)
+	default:
+		return s.issueClientCredentials(client, req)
+	}
+}
+
+func (s *oauthServerService) exchangeAuthorizationCode(ctx context.Context, client *models.OAuthClient, req TokenRequest, clientInfo ClientInfo) (*OAuthTokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, oauthError(http.StatusBadRequest, "invalid_request", "Thiếu code hoặc code_verifier")
+	}
+
+	code, err := s.codeRepo.FindByHash(ctx, utils.HashToken(req.Code))
+	if err != nil || code.ClientID != client.ClientID {
+		return nil, errInvalidGrant
+	}
+
+	// Code bị trình lại: có thể đã lộ, thu hồi luôn phiên đã cấp từ nó
+	if code.UsedAt != nil {
+		logger.Warn("Phát hiện authorization code bị dùng lại",
+			zap.String("event", "authorization_code_reuse"),
+			zap.String("client_id", client.ClientID),
+			zap.Uint("user_id", code.UserID),
+		)
+		if code.SessionID != nil {
+			_ = s.sessionRepo.Revoke(ctx, *code.SessionID)
+		}
+		return nil, errInvalidGrant
+	}
+
+	if code.ExpiresAt.Before(time.Now()) || code.RedirectURI != req.RedirectURI {
+		return nil, errInvalidGrant
+	}
+	if subtle.ConstantTimeCompare([]byte(oauth.CodeChallenge(req.CodeVerifier)), []byte(code.CodeChallenge)) != 1 {
+		return nil, errInvalidGrant
+	}
+
+	// User bị đình chỉ sau khi đồng ý: không tạo phiên và token mới từ code còn treo
+	user, err := s.repo.FindByID(ctx, code.UserID)
+	if err != nil || user.IsSuspended() {
+		return nil, errInvalidGrant
+	}
+
+	// Mỗi lần cấp là một phiên riêng, user thấy và thu hồi được trong danh sách thiết bị.
+	// Không xin offline_access thì phiên chỉ sống bằng Access Token
+	scopes := strings.Fields(code.Scope)
+	ttl := accessTTL()
+	if hasScope(scopes, models.ScopeOfflineAccess) {
+		ttl = refreshTTL()
+	}
+
+	session := newSession(user.ID, clientInfo, ttl)
+	session.AuthenticatedAt = code.AuthTime
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	used, err := s.codeRepo.MarkUsed(ctx, code.ID, session.ID)
+	if err != nil || !used {
+		// Một request khác vừa đổi code này trước
+		_ = s.sessionRepo.Revoke(ctx, session.ID)
+		return nil, errInvalidGrant
+	}
+
+	return s.issueUserTokens(ctx, user, session, client, scopes, code.Nonce, code.AuthTime)
+}
+
+func (s *oauthServerService) exchangeRefreshToken(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*OAuthTokenResponse, error) {
+	claims, err := s.keys.Parse(req.RefreshToken)
+	if err != nil || claims["token_type"] != "refresh" {
+		return nil, errInvalidGrant
+	}
+
+	// Dùng chung cơ chế xoay vòng + phát hiện tái sử dụng với Refresh Token của hệ thống
+	user, session, err := rotateRefreshSession(ctx, s.repo, s.sessionRepo, claims, client.ClientID)
+	if err != nil {
+		return nil, errInvalidGrant
+	}
+
+	scope, _ := claims["scope"].(string)
+	authTime := time.Now()
+	if value, ok := claims["auth_time"].(float64); ok {
+		authTime = time.Unix(int64(value), 0)
+	}
+	return s.issueUserTokens(ctx, user, session, client, strings.Fields(scope), "", authTime)
+}
+
+// issueClientCredentials cấp token cho chính ứng dụng (không có user), chỉ mang các scope API
+func (s *oauthServerService) issueClientCredentials(client *models.OAuthClient, req TokenRequest) (*OAuthTokenResponse, error) {
+	if client.Public {
+		return nil, oauthError(http.StatusBadRequest, "unauthorized_client", "Client công khai không được dùng client_credentials")
+	}
+
+	var scopes []string
+	if req.Scope == "" {
+		for _, scope := range client.Scopes {
+			if hasScope(models.AllScopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	} else {
+		for _, scope := range strings.Fields(req.Scope) {
+			if !hasScope(models.AllScopes, scope) || !client.AllowsScope(scope) {
+				return nil, oauthError(http.StatusBadRequest, "invalid_scope", "Scope không được phép: "+scope)
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(accessTTL())
+	token, err := s.keys.Sign(jwt.MapClaims{
+		"token_type": "client_access",
+		"iss":        oidcIssuer(),
+		"sub":        client.ClientID,
+		"client_id":  client.ClientID,
+		"scope":      strings.Join(scopes, " "),
+		"iat":        now.Unix(),
+		"exp":        expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	return &OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(accessTTL().Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// issueUserTokens ký Access Token (kèm scope), Refresh Token (khi có offline_access) và ID Token (khi có openid)
+func (s *oauthServerService) issueUserTokens(
+	ctx context.Context,
+	user *models.User,
+	session *models.Session,
+	client *models.OAuthClient,
+	scopes []string,
+	nonce string,
+	authTime time.Time,
+) (*OAuthTokenResponse, error) {
+	now := time.Now()
+	expiresAt := now.Add(accessTTL())
+	scope := strings.Join(scopes, " ")
+	subject := strconv.FormatUint(uint64(user.ID), 10)
+
+	accessClaims := jwt.MapClaims{
+		"token_type":    "access",
+		"iss":           oidcIssuer(),
+		"sub":           subject,
+		"aud":           client.ClientID,
+		"client_id":     client.ClientID,
+		"scope":         scope,
+		"user_id":       user.ID,
+		"role":          user.Role,
+		"token_version": user.TokenVersion,
+		"session_id":    session.ID,
+		"iat":           now.Unix(),
+		"exp":           expiresAt.Unix(),
+	}
+	if err := addPermissionClaims(ctx, s.roleRepo, user.Role, accessClaims); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.keys.Sign(accessClaims)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	resp := &OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(accessTTL().Seconds()),
+		Scope:       scope,
+	}
+
+	if hasScope(scopes, models.ScopeOfflineAccess) {
+		resp.RefreshToken, err = s.keys.Sign(jwt.MapClaims{
+			"token_type":    "refresh",
+			"jti":           session.RefreshTokenID,
+			"family_id":     session.FamilyID,
+			"user_id":       user.ID,
+			"token_version": user.TokenVersion,
+			"session_id":    session.ID,
+			"client_id":     client.ClientID,
+			"scope":         scope,
+			"auth_time":     authTime.Unix(),
+			"exp":           session.ExpiresAt.Unix(),
+		})
+		if err != nil {
+			return nil, custom_error.ErrInternalServer
+		}
+	}
+
+	if hasScope(scopes, models.ScopeOpenID) {
+		idClaims := jwt.MapClaims{
+			"iss":       oidcIssuer(),
+			"aud":       client.ClientID,
+			"azp":       client.ClientID,
+			"iat":       now.Unix(),
+			"exp":       expiresAt.Unix(),
+			"auth_time": authTime.Unix(),
+		}
+		if nonce != "" {
+			idClaims["nonce"] = nonce
+		}
+		for key, value := range userClaims(user, scopes) {
+			idClaims[key] = value
+		}
+
+		resp.IDToken, err = s.keys.Sign(idClaims)
+		if err != nil {
+			return nil, custom_error.ErrInternalServer
+		}
+	}
+
+	return resp, nil
+}
+
+// userClaims trả về các claim chuẩn OIDC của user theo scope đã được đồng ý
+func userClaims(user *models.User, scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": strconv.FormatUint(uint64(user.ID), 10),
+	}
+	if hasScope(scopes, models.ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerifiedAt != nil
+	}
+	if hasScope(scopes, models.ScopeProfile) {
+		claims["name"] = user.FullName
+		claims["updated_at"] = user.UpdatedAt.Unix()
+		if user.Avatar != "" {
+			picture := user.Avatar
+			// Avatar upload lưu đường dẫn tương đối (uploads/...), client bên ngoài cần URL đầy đủ
+			if !strings.HasPrefix(picture, "http://") && !strings.HasPrefix(picture, "https://") {
+				picture = oidcIssuer() + "/" + strings.TrimLeft(picture, "/")
+			}
+			claims["picture"] = picture
+		}
+	}
+	return claims
+}
+
+// ============================================================================
+// /userinfo và discovery
+// ============================================================================
+
+func (s *oauthServerService) UserInfo(ctx context.Context, userID uint, scopes []string) (map[string]interface{}, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	return userClaims(user, scopes), nil
+}
+
+// Discovery trả về metadata theo OpenID Connect Discovery 1.0
+func (s *oauthServerService) Discovery() map[string]interface{} {
+	issuer := oidcIssuer()
+	scopes := append(append([]string{}, models.OIDCScopes...), models.AllScopes...)
+
+	return map[string]interface{}{
+		"issuer":                                         issuer,
+		"authorization_endpoint":                         issuer + "/oauth/authorize",
+		"token_endpoint":                                 issuer + "/oauth/token",
+		"userinfo_endpoint":                              issuer + "/userinfo",
+		"introspection_endpoint":                         issuer + "/oauth/introspect",
+		"revocation_endpoint":                            issuer + "/oauth/revoke",
+		"jwks_uri":                                       issuer + "/.well-known/jwks.json",
+		"scopes_supported":                               scopes,
+		"response_types_supported":                       []string{"code"},
+		"grant_types_supported":                          []string{models.GrantAuthorizationCode, models.GrantRefreshToken, models.GrantClientCredentials},
+		"subject_types_supported":                        []string{"public"},
+		"id_token_signing_alg_values_supported":          []string{s.keys.SigningAlgorithm()},
+		"token_endpoint_auth_methods_supported":          []string{"client_secret_basic", "client_secret_post", "none"},
+		"introspection_endpoint_auth_methods_supported":  []string{"client_secret_basic", "client_secret_post"},
+		"revocation_endpoint_auth_methods_supported":     []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":               []string{"S256"},
+		"claims_supported":                               []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "name", "picture", "updated_at"},
+		"authorization_response_iss_parameter_supported": true,
+	}
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/jwtkeys"
+	"go-core-api/pkg/logger"
+	"go-core-api/pkg/mailer"
+	"go-core-api/pkg/utils"
+	"go-core-api/templates"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const defaultOrgInvitationPath = "/accept-org-invitation"
+
+// OrganizationMember là thông tin thành viên hiển thị cho người cùng tổ chức, chỉ gồm dữ liệu công khai
+type OrganizationMember struct {
+	ID       uint   `json:"id"` // ID của user
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"` // Role trong tổ chức
+}
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, userID uint, name string) (*models.Membership, error)
+	ListMyOrganizations(ctx context.Context, userID uint) ([]models.Membership, error)
+	SwitchOrganization(ctx context.Context, userID, sessionID, organizationID uint) (*TokenDetails, error)
+	ListMembers(ctx context.Context, organizationID uint) ([]OrganizationMember, error)
+	InviteMember(ctx context.Context, organizationID, inviterID uint, actorRole, email, role string) (*models.Invitation, error)
+	AcceptInvitation(ctx context.Context, userID uint, token string) (*models.Membership, error)
+	UpdateMemberRole(ctx context.Context, organizationID uint, actorRole string, userID uint, role string) error
+	RemoveMember(ctx context.Context, organizationID uint, actorRole string, userID uint) error
+}
+
+type organizationService struct {
+	repo           repositories.OrganizationRepository
+	userRepo       repositories.UserRepository
+	sessionRepo    repositories.SessionRepository
+	invitationRepo repositories.InvitationRepository
+	auth           AuthService
+	keys           *jwtkeys.KeySet
+	mailer         mailer.Mailer
+}
+
+func NewOrganizationService(
+	repo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	invitationRepo repositories.InvitationRepository,
+	auth AuthService,
+	keys *jwtkeys.KeySet,
+	mail mailer.Mailer,
+) OrganizationService {
+	return &organizationService{
+		repo:           repo,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		invitationRepo: invitationRepo,
+		auth:           auth,
+		keys:           keys,
+		mailer:         mail,
+	}
+}
+
+// CreateOrganization tạo tổ chức mới, người tạo trở thành owner
+func (s *organizationService) CreateOrganization(ctx context.Context, userID uint, name string) (*models.Membership, error) {
+	organization := &models.Organization{Name: name, CreatedBy: userID}
+	membership := &models.Membership{UserID: userID, Role: models.OrgRoleOwner}
+
+	if err := s.repo.Create(ctx, organization, membership); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	membership.Organization = organization
+	return membership, nil
+}
+
+func (s *organizationService) ListMyOrganizations(ctx context.Context, userID uint) ([]models.Membership, error) {
+	memberships, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return memberships, nil
+}
+
+// SwitchOrganization đổi tổ chức đang làm việc của phiên hiện tại và cấp lại token mang claim org_id mới.
+// organizationID = 0 để rời khỏi mọi tổ chức (quay về phạm vi cá nhân)
+func (s *organizationService) SwitchOrganization(ctx context.Context, userID, sessionID, organizationID uint) (*TokenDetails, error) {
+	var active *uint
+	if organizationID != 0 {
+		if _, err := s.repo.FindMembership(ctx, organizationID, userID); err != nil {
+			return nil, custom_error.ErrOrganizationNotFound
+		}
+		active = &organizationID
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || !session.IsActive() {
+		return nil, custom_error.ErrSessionRevoked
+	}
+
+	if err := s.sessionRepo.SetOrganization(ctx, session.ID, active); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	session.OrganizationID = active
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	return s.auth.GenerateTokens(ctx, user, session)
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, organizationID uint) ([]OrganizationMember, error) {
+	memberships, err := s.repo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	members := make([]OrganizationMember, 0, len(memberships))
+	for _, membership := range memberships {
+		member := OrganizationMember{ID: membership.UserID, Role: membership.Role}
+		if membership.User != nil {
+			member.FullName = membership.User.FullName
+			member.Email = membership.User.Email
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// InviteMember gửi lời mời tham gia tổ chức qua email. Người nhận chỉ trở thành thành viên sau khi tự chấp nhận,
+// và phản hồi giống nhau dù email đã có tài khoản hay chưa (không dò được email nào đã đăng ký)
+func (s *organizationService) InviteMember(ctx context.Context, organizationID, inviterID uint, actorRole, email, role string) (*models.Invitation, error) {
+	if err := checkAssignableOrgRole(actorRole, role); err != nil {
+		return nil, err
+	}
+
+	organization, err := s.repo.FindByID(ctx, organizationID)
+	if err != nil {
+		return nil, custom_error.ErrOrganizationNotFound
+	}
+
+	// Thành viên hiện tại đã hiện trong danh sách thành viên nên báo trùng không làm lộ thêm thông tin
+	if user, err := s.userRepo.FindByEmail(ctx, email); err == nil {
+		if _, err := s.repo.FindMembership(ctx, organizationID, user.ID); err == nil {
+			return nil, custom_error.ErrMemberExists
+		}
+	}
+	if _, err := s.invitationRepo.FindPendingMembership(ctx, organizationID, email); err == nil {
+		return nil, custom_error.ErrInvitationExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	now := time.Now()
+	invitation := &models.Invitation{
+		Email:          email,
+		OrganizationID: &organizationID,
+		OrgRole:        role,
+		TokenID:        uuid.NewString(),
+		InvitedBy:      inviterID,
+		ExpiresAt:      now.Add(defaultInvitationDays * 24 * time.Hour),
+		SentAt:         now,
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	if err := s.sendInvitationEmail(organization, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation thêm user đang đăng nhập vào tổ chức theo lời mời. Email của tài khoản phải trùng email được mời
+func (s *organizationService) AcceptInvitation(ctx context.Context, userID uint, token string) (*models.Membership, error) {
+	claims, err := s.keys.Parse(token)
+	if err != nil || claims["token_type"] != "org_invitation" {
+		return nil, custom_error.ErrInvalidInvitation
+	}
+
+	invitationIDFloat, okID := claims["invitation_id"].(float64)
+	tokenID, okJTI := claims["jti"].(string)
+	if !okID || !okJTI {
+		return nil, custom_error.ErrInvalidInvitation
+	}
+
+	// Lời mời thuộc tổ chức khác với tổ chức đang làm việc của phiên, bỏ phạm vi tenant khi tra cứu
+	invitation, err := s.invitationRepo.FindByID(repositories.WithTenant(ctx, 0), uint(invitationIDFloat))
+	if err != nil || invitation.TokenID != tokenID || !invitation.IsUsable() || !invitation.IsMembershipOnly() || invitation.OrganizationID == nil {
+		return nil, custom_error.ErrInvalidInvitation
+	}
+
+	user, err := s.userRepo.FindByID(repositories.WithTenant(ctx, 0), userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, custom_error.ErrInvalidInvitation
+	}
+
+	organizationID := *invitation.OrganizationID
+	if _, err := s.repo.FindMembership(ctx, organizationID, user.ID); err == nil {
+		return nil, custom_error.ErrMemberExists
+	}
+
+	membership := &models.Membership{OrganizationID: organizationID, UserID: user.ID, Role: invitation.OrgRole}
+	if err := s.repo.AddMember(ctx, membership); err != nil {
+		// Unique index (organization_id, user_id): một request chấp nhận khác vừa thêm trước
+		return nil, custom_error.ErrMemberExists
+	}
+
+	now := time.Now()
+	invitation.AcceptedAt = &now
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	if organization, err := s.repo.FindByID(ctx, organizationID); err == nil {
+		membership.Organization = organization
+	}
+	return membership, nil
+}
+
+// sendInvitationEmail ký link mời vào tổ chức và gửi ngầm qua Worker Pool
+func (s *organizationService) sendInvitationEmail(organization *models.Organization, invitation *models.Invitation) error {
+	token, err := s.keys.Sign(jwt.MapClaims{
+		"token_type":    "org_invitation",
+		"invitation_id": invitation.ID,
+		"jti":           invitation.TokenID,
+		"exp":           invitation.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		logger.Error("Lỗi ký link mời vào tổ chức", zap.Error(err))
+		return custom_error.ErrInternalServer
+	}
+
+	baseURL := config.AppConfig.Auth.OrgInvitationURL
+	if baseURL == "" {
+		baseURL = config.AppConfig.Server.Domain + defaultOrgInvitationPath
+	}
+	link := baseURL + "?token=" + url.QueryEscape(token)
+	data := map[string]interface{}{
+		"Email":        invitation.Email,
+		"Organization": organization.Name,
+		"Role":         invitation.OrgRole,
+		"Link":         link,
+		"ExpiresAt":    invitation.ExpiresAt.Format("02/01/2006 15:04"),
+	}
+
+	utils.RunInBackground(func() {
+		subject := "📨 You've been invited to join " + organization.Name
+		body, err := templates.Render("org_invitation.html", data)
+		if err != nil {
+			logger.Error("Lỗi render template mời vào tổ chức", zap.Error(err))
+			return
+		}
+		if err := s.mailer.SendMail(invitation.Email, subject, body); err != nil {
+			logger.Error("Lỗi gửi email mời vào tổ chức", zap.Error(err))
+		}
+	})
+
+	return nil
+}
+
+func (s *organizationService) UpdateMemberRole(ctx context.Context, organizationID uint, actorRole string, userID uint, role string) error {
+	if err := checkAssignableOrgRole(actorRole, role); err != nil {
+		return err
+	}
+
+	membership, err := s.findManageableMember(ctx, organizationID, actorRole, userID)
+	if err != nil {
+		return err
+	}
+
+	if membership.Role == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+
+	membership.Role = role
+	if err := s.repo.UpdateMemberRole(ctx, membership); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, organizationID uint, actorRole string, userID uint) error {
+	membership, err := s.findManageableMember(ctx, organizationID, actorRole, userID)
+	if err != nil {
+		return err
+	}
+
+	if membership.Role == models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RemoveMember(ctx, organizationID, userID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// findManageableMember lấy membership cần sửa/xoá. Chỉ owner mới được động vào owner khác
+func (s *organizationService) findManageableMember(ctx context.Context, organizationID uint, actorRole string, userID uint) (*models.Membership, error) {
+	membership, err := s.repo.FindMembership(ctx, organizationID, userID)
+	if err != nil {
+		return nil, custom_error.ErrMemberNotFound
+	}
+	if membership.Role == models.OrgRoleOwner && actorRole != models.OrgRoleOwner {
+		return nil, custom_error.ErrForbidden
+	}
+	return membership, nil
+}
+
+// ensureAnotherOwner chặn việc gỡ owner cuối cùng, tránh tổ chức không còn ai quản lý
+func (s *organizationService) ensureAnotherOwner(ctx context.Context, organizationID uint) error {
+	owners, err := s.repo.CountOwners(ctx, organizationID)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if owners <= 1 {
+		return custom_error.ErrLastOwner
+	}
+	return nil
+}
+
+// checkAssignableOrgRole: role phải hợp lệ và chỉ owner mới được cấp quyền owner
+func checkAssignableOrgRole(actorRole, role string) error {
+	if !models.IsValidOrgRole(role) {
+		return custom_error.ErrInvalidOrgRole
+	}
+	if role == models.OrgRoleOwner && actorRole != models.OrgRoleOwner {
+		return custom_error.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/breached"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/hasher"
+	"go-core-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Mã lý do trong custom_error.FieldError khi mật khẩu bị từ chối
+const (
+	PasswordTooShort      = "too_short"
+	PasswordTooLong       = "too_long"
+	PasswordMissingUpper  = "missing_upper"
+	PasswordMissingLower  = "missing_lower"
+	PasswordMissingDigit  = "missing_digit"
+	PasswordMissingSymbol = "missing_symbol"
+	PasswordContainsEmail = "contains_email"
+	PasswordReused        = "reused"
+	PasswordBreached      = "breached"
+)
+
+// PasswordPolicy là chính sách mật khẩu dùng chung cho đăng ký, reset và đổi mật khẩu
+type PasswordPolicy interface {
+	// Validate kiểm tra mật khẩu mới của user (user.ID = 0 khi đang đăng ký).
+	// field là tên field trong request để Frontend gắn lỗi đúng ô nhập
+	Validate(ctx context.Context, field, password string, user *models.User) error
+	// Remember lưu hash vừa đặt vào lịch sử để chặn tái sử dụng về sau
+	Remember(ctx context.Context, userID uint, hash string)
+}
+
+type passwordPolicy struct {
+	historyRepo repositories.PasswordHistoryRepository
+	hasher      hasher.PasswordHasher
+	breached    breached.Checker
+}
+
+func NewPasswordPolicy(historyRepo repositories.PasswordHistoryRepository, passwordHasher hasher.PasswordHasher, checker breached.Checker) PasswordPolicy {
+	return &passwordPolicy{
+		historyRepo: historyRepo,
+		hasher:      passwordHasher,
+		breached:    checker,
+	}
+}
+
+// passwordRules đọc cấu hình, điền giá trị mặc định nếu bỏ trống
+func passwordRules() (minLength, maxLength, historySize int) {
+	cfg := config.AppConfig.PasswordPolicy
+	minLength, maxLength, historySize = cfg.MinLength, cfg.MaxLength, cfg.HistorySize
+
+	if minLength <= 0 {
+		minLength = 10
+	}
+	if maxLength <= 0 {
+		maxLength = 128
+	}
+	if historySize <= 0 {
+		historySize = 5
+	}
+	return
+}
+
+func (p *passwordPolicy) Validate(ctx context.Context, field, password string, user *models.User) error {
+	cfg := config.AppConfig.PasswordPolicy
+	minLength, maxLength, historySize := passwordRules()
+
+	var details []custom_error.FieldError
+	reject := func(code, message string) {
+		details = append(details, custom_error.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	// 1. Độ dài tính theo ký tự (không phải byte) để mật khẩu tiếng Việt có dấu không bị thiệt
+	length := len([]rune(password))
+	if length < minLength {
+		reject(PasswordTooShort, fmt.Sprintf("Mật khẩu phải có ít nhất %d ký tự", minLength))
+	}
+	if length > maxLength {
+		reject(PasswordTooLong, fmt.Sprintf("Mật khẩu không được dài quá %d ký tự", maxLength))
+	}
+
+	// 2. Các nhóm ký tự bắt buộc
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if cfg.RequireUpper && !hasUpper {
+		reject(PasswordMissingUpper, "Mật khẩu phải có ít nhất một chữ in hoa")
+	}
+	if cfg.RequireLower && !hasLower {
+		reject(PasswordMissingLower, "Mật khẩu phải có ít nhất một chữ thường")
+	}
+	if cfg.RequireDigit && !hasDigit {
+		reject(PasswordMissingDigit, "Mật khẩu phải có ít nhất một chữ số")
+	}
+	if cfg.RequireSymbol && !hasSymbol {
+		reject(PasswordMissingSymbol, "Mật khẩu phải có ít nhất một ký tự đặc biệt")
+	}
+
+	// 3. Không được trùng hoặc chứa email
+	if containsEmail(password, user.Email) {
+		reject(PasswordContainsEmail, "Mật khẩu không được trùng hoặc chứa địa chỉ email")
+	}
+
+	// 4. Nằm trong danh sách mật khẩu đã bị lộ
+	if p.breached.IsBreached(password) {
+		reject(PasswordBreached, "Mật khẩu này đã xuất hiện trong các vụ lộ dữ liệu, hãy chọn mật khẩu khác")
+	}
+
+	// Các lỗi trên đã đủ để từ chối, không cần tốn thêm chi phí so hash lịch sử
+	if len(details) > 0 {
+		return custom_error.ErrWeakPassword.WithDetails(details...)
+	}
+
+	// 5. Không dùng lại mật khẩu hiện tại và N mật khẩu gần nhất
+	if user.ID != 0 {
+		reused, err := p.isReused(ctx, password, user, historySize)
+		if err != nil {
+			return custom_error.ErrInternalServer
+		}
+		if reused {
+			reject(PasswordReused, fmt.Sprintf("Không được dùng lại %d mật khẩu gần nhất", historySize))
+			return custom_error.ErrWeakPassword.WithDetails(details...)
+		}
+	}
+
+	return nil
+}
+
+func (p *passwordPolicy) isReused(ctx context.Context, password string, user *models.User, historySize int) (bool, error) {
+	// Hash hiện tại được kiểm tra riêng vì tài khoản cũ có thể chưa có lịch sử
+	hashes := []string{user.Password}
+
+	entries, err := p.historyRepo.ListRecent(ctx, user.ID, historySize)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		hashes = append(hashes, entry.Hash)
+	}
+
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		if match, _, _ := p.hasher.Verify(password, hash); match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *passwordPolicy) Remember(ctx context.Context, userID uint, hash string) {
+	_, _, historySize := passwordRules()
+
+	if err := p.historyRepo.Add(ctx, &models.PasswordHistory{UserID: userID, Hash: hash}); err != nil {
+		logger.Error("Lỗi lưu lịch sử mật khẩu", zap.Uint("user_id", userID), zap.Error(err))
+		return
+	}
+	if err := p.historyRepo.Prune(ctx, userID, historySize); err != nil {
+		logger.Error("Lỗi dọn lịch sử mật khẩu", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+// containsEmail so không phân biệt hoa thường với cả địa chỉ email và phần trước "@"
+func containsEmail(password, email string) bool {
+	if email == "" {
+		return false
+	}
+
+	lowered := strings.ToLower(password)
+	email = strings.ToLower(email)
+	if lowered == email {
+		return true
+	}
+
+	local, _, _ := strings.Cut(email, "@")
+	// Phần tên quá ngắn (vd "an@...") sẽ bắt nhầm quá nhiều mật khẩu hợp lệ
+	return len(local) >= 4 && strings.Contains(lowered, local)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/utils"
+)
+
+const (
+	defaultTokenLifetimeDays = 90
+	maxTokenLifetimeDays     = 365
+	maxTokensPerUser         = 20
+)
+
+// CreatedPersonalToken trả về token gốc đúng một lần duy nhất lúc tạo
+type CreatedPersonalToken struct {
+	Token string                      `json:"token"`
+	Info  *models.PersonalAccessToken `json:"info"`
+}
+
+type PersonalAccessTokenService interface {
+	CreateToken(ctx context.Context, userID uint, name string, scopes []string, expiresInDays int) (*CreatedPersonalToken, error)
+	ListTokens(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error)
+	RevokeToken(ctx context.Context, userID uint, id uint) error
+}
+
+type personalAccessTokenService struct {
+	repo repositories.PersonalAccessTokenRepository
+}
+
+func NewPersonalAccessTokenService(repo repositories.PersonalAccessTokenRepository) PersonalAccessTokenService {
+	return &personalAccessTokenService{repo: repo}
+}
+
+func (s *personalAccessTokenService) CreateToken(ctx context.Context, userID uint, name string, scopes []string, expiresInDays int) (*CreatedPersonalToken, error) {
+	if err := validateScopes(scopes); err != nil {
+		return nil, err
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = defaultTokenLifetimeDays
+	}
+	if expiresInDays < 0 || expiresInDays > maxTokenLifetimeDays {
+		return nil, custom_error.ErrInvalidTokenExpiry
+	}
+
+	existing, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	if len(existing) >= maxTokensPerUser {
+		return nil, custom_error.ErrTooManyTokens
+	}
+
+	plain, prefix, err := utils.GeneratePersonalToken()
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: utils.HashToken(plain),
+		Scopes:    scopes,
+		ExpiresAt: &expiresAt,
+	}
+	if err := s.repo.Create(ctx, token); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	return &CreatedPersonalToken{Token: plain, Info: token}, nil
+}
+
+func (s *personalAccessTokenService) ListTokens(ctx context.Context, userID uint) ([]models.PersonalAccessToken, error) {
+	tokens, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return tokens, nil
+}
+
+func (s *personalAccessTokenService) RevokeToken(ctx context.Context, userID uint, id uint) error {
+	revoked, err := s.repo.Revoke(ctx, userID, id)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if !revoked {
+		return custom_error.ErrTokenNotFound
+	}
+	return nil
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return custom_error.ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		valid := false
+		for _, allowed := range models.AllScopes {
+			if scope == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return custom_error.ErrInvalidScope
+		}
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Tên role dùng làm giá trị của users.role và claim "role" trong token
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// RoleInput là dữ liệu tạo/cập nhật role. Tên role không đổi được sau khi tạo
+type RoleInput struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+type RoleService interface {
+	SyncDefaults(ctx context.Context) error
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	CreateRole(ctx context.Context, actorID uint, input RoleInput) (*models.Role, error)
+	UpdateRole(ctx context.Context, actorID, id uint, input RoleInput) (*models.Role, error)
+	DeleteRole(ctx context.Context, id uint) error
+}
+
+type roleService struct {
+	repo     repositories.RoleRepository
+	userRepo repositories.UserRepository
+}
+
+func NewRoleService(repo repositories.RoleRepository, userRepo repositories.UserRepository) RoleService {
+	return &roleService{repo: repo, userRepo: userRepo}
+}
+
+// SyncDefaults đồng bộ danh mục quyền và đảm bảo 2 role dựng sẵn tồn tại.
+// Role admin luôn có toàn bộ quyền, kể cả các quyền mới được thêm vào code
+func (s *roleService) SyncDefaults(ctx context.Context) error {
+	if err := s.repo.SyncPermissions(ctx, models.PermissionCatalog); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(models.PermissionCatalog))
+	for _, permission := range models.PermissionCatalog {
+		names = append(names, permission.Name)
+	}
+	allPermissions, err := s.repo.FindPermissions(ctx, names)
+	if err != nil {
+		return err
+	}
+
+	defaults := []models.Role{
+		{Name: models.RoleUser, Description: "Người dùng thông thường", System: true},
+		{Name: models.RoleAdmin, Description: "Quản trị viên, có toàn bộ quyền", System: true, Permissions: allPermissions},
+	}
+	for _, role := range defaults {
+		existing, err := s.repo.FindByName(ctx, role.Name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			role := role
+			if err := s.repo.Create(ctx, &role); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if role.Name == models.RoleAdmin && len(existing.Permissions) != len(allPermissions) {
+			existing.Permissions = allPermissions
+			existing.PermissionVersion += 1
+			if err := s.repo.Update(ctx, existing); err != nil {
+				return err
+			}
+			logger.Info("Đã cấp thêm quyền mới cho role admin", zap.Int("permissions", len(allPermissions)))
+		}
+	}
+	return nil
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return roles, nil
+}
+
+func (s *roleService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	permissions, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return permissions, nil
+}
+
+// CreateRole chỉ cho cấp những quyền mà role của người thao tác đang có,
+// tránh việc người giữ roles:manage tự tạo role mạnh hơn rồi nhờ người khác gán
+func (s *roleService) CreateRole(ctx context.Context, actorID uint, input RoleInput) (*models.Role, error) {
+	if !roleNamePattern.MatchString(input.Name) {
+		return nil, custom_error.ErrInvalidRole.WithDetails(custom_error.FieldError{
+			Field: "name", Code: "invalid", Message: "Tên role chỉ gồm chữ thường, số, '_' hoặc '-', dài 2-50 ký tự",
+		})
+	}
+	if _, err := s.repo.FindByName(ctx, input.Name); err == nil {
+		return nil, custom_error.ErrRoleExists
+	}
+
+	_, actorRole, err := s.findActor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.resolvePermissions(ctx, input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrantable(actorRole, permissions); err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{Name: input.Name, Description: input.Description, Permissions: permissions}
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return role, nil
+}
+
+// UpdateRole đổi mô tả và danh sách quyền. Khi quyền thay đổi, PermissionVersion tăng lên
+// để Access Token đang lưu hành của user thuộc role này phải refresh lấy quyền mới.
+// Người thao tác không được sửa role của chính mình và chỉ sửa được role có quyền nằm trong quyền của mình
+func (s *roleService) UpdateRole(ctx context.Context, actorID, id uint, input RoleInput) (*models.Role, error) {
+	role, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, custom_error.ErrRoleNotFound
+	}
+	// Không cho sửa quyền của admin để tránh tự khoá mình khỏi trang quản trị
+	if role.Name == models.RoleAdmin {
+		return nil, custom_error.ErrSystemRole
+	}
+
+	actor, actorRole, err := s.findActor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == actor.Role {
+		return nil, custom_error.ErrCannotEditOwnRole
+	}
+	if !coversPermissions(actorRole, role) {
+		return nil, custom_error.ErrForbidden
+	}
+
+	permissions, err := s.resolvePermissions(ctx, input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrantable(actorRole, permissions); err != nil {
+		return nil, err
+	}
+
+	if !samePermissions(role.Permissions, permissions) {
+		role.PermissionVersion += 1
+	}
+	role.Description = input.Description
+	role.Permissions = permissions
+
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return role, nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, id uint) error {
+	role, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return custom_error.ErrRoleNotFound
+	}
+	if role.System {
+		return custom_error.ErrSystemRole
+	}
+
+	assigned, err := s.repo.CountUsers(ctx, role.Name)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if assigned > 0 {
+		return custom_error.ErrRoleInUse
+	}
+
+	if err := s.repo.Delete(ctx, role.ID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// resolvePermissions đổi tên quyền thành bản ghi trong DB, báo lỗi theo từng quyền không tồn tại
+func (s *roleService) resolvePermissions(ctx context.Context, names []string) ([]models.Permission, error) {
+	if len(names) == 0 {
+		return []models.Permission{}, nil
+	}
+
+	permissions, err := s.repo.FindPermissions(ctx, names)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+
+	var details []custom_error.FieldError
+	for _, name := range names {
+		if !found[name] {
+			details = append(details, custom_error.FieldError{Field: "permissions", Code: "unknown", Message: "Quyền không tồn tại: " + name})
+		}
+	}
+	if len(details) > 0 {
+		return nil, custom_error.ErrInvalidRole.WithDetails(details...)
+	}
+	return permissions, nil
+}
+
+// findActor lấy người thao tác cùng role hiện tại của họ để so quyền
+func (s *roleService) findActor(ctx context.Context, actorID uint) (*models.User, *models.Role, error) {
+	actor, err := s.userRepo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, nil, custom_error.ErrUnauthorized
+	}
+	actorRole, err := s.repo.FindByName(ctx, actor.Role)
+	if err != nil {
+		return nil, nil, custom_error.ErrForbidden
+	}
+	return actor, actorRole, nil
+}
+
+// checkGrantable báo lỗi theo từng quyền mà role của người thao tác không có
+func checkGrantable(actorRole *models.Role, permissions []models.Permission) error {
+	var details []custom_error.FieldError
+	for _, permission := range permissions {
+		if !actorRole.HasPermission(permission.Name) {
+			details = append(details, custom_error.FieldError{Field: "permissions", Code: "forbidden", Message: "Bạn không có quyền: " + permission.Name})
+		}
+	}
+	if len(details) > 0 {
+		return custom_error.ErrForbidden.WithDetails(details...)
+	}
+	return nil
+}
+
+func samePermissions(current, next []models.Permission) bool {
+	if len(current) != len(next) {
+		return false
+	}
+	ids := make(map[uint]bool, len(current))
+	for _, permission := range current {
+		ids[permission.ID] = true
+	}
+	for _, permission := range next {
+		if !ids[permission.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// addPermissionClaims gắn quyền của role vào Access Token để service khác đọc trực tiếp.
+// perm_version giúp RequireAuth từ chối token được cấp trước khi quyền của role thay đổi
+func addPermissionClaims(ctx context.Context, roleRepo repositories.RoleRepository, roleName string, claims jwt.MapClaims) error {
+	role, err := roleRepo.FindByName(ctx, roleName)
+	if err != nil {
+		logger.Error("Không tìm thấy role của user", zap.String("role", roleName), zap.Error(err))
+		return custom_error.ErrInternalServer
+	}
+
+	claims["permissions"] = role.PermissionNames()
+	claims["perm_version"] = role.PermissionVersion
+	return nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/custom_error"
+)
+
+type SessionService interface {
+	ListSessions(ctx context.Context, userID uint, currentSessionID uint) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID uint, sessionID uint) error
+	RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID uint) error
+}
+
+type sessionService struct {
+	repo repositories.SessionRepository
+}
+
+func NewSessionService(repo repositories.SessionRepository) SessionService {
+	return &sessionService{repo: repo}
+}
+
+// ListSessions trả về các thiết bị đang đăng nhập, đánh dấu phiên của request hiện tại
+func (s *sessionService) ListSessions(ctx context.Context, userID uint, currentSessionID uint) ([]models.Session, error) {
+	sessions, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession đăng xuất một thiết bị cụ thể của chính user đó
+func (s *sessionService) RevokeSession(ctx context.Context, userID uint, sessionID uint) error {
+	session, err := s.repo.FindByID(ctx, sessionID)
+	// Không tiết lộ phiên của người khác: trả về 404 như thể không tồn tại
+	if err != nil || session.UserID != userID {
+		return custom_error.ErrSessionNotFound
+	}
+
+	if err := s.repo.Revoke(ctx, session.ID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// RevokeOtherSessions đăng xuất khỏi mọi thiết bị khác, giữ lại phiên hiện tại
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID uint) error {
+	if err := s.repo.RevokeAllByUser(ctx, userID, currentSessionID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
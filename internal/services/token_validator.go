@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/custom_error"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// UserTokenClaims là các claim của Access/Refresh Token cấp cho user (đã verify chữ ký)
+type UserTokenClaims struct {
+	TokenType      string
+	UserID         uint
+	TokenVersion   int
+	SessionID      uint
+	ClientID       string
+	ActorID        uint // Người thực sự gửi request: admin khi là token giả lập, ngược lại bằng UserID
+	Impersonating  bool
+	OrganizationID uint // 0 khi token không gắn tổ chức đang làm việc
+	PermVersion    int
+	TokenID        string // jti và family_id chỉ có ở Refresh Token
+	FamilyID       string
+}
+
+// ParseUserTokenClaims ép kiểu an toàn các claim của token user, không truy vấn database.
+// Tách riêng khỏi Validate để RequireAuth biết token giả lập và ghi nhật ký cả khi Validate từ chối
+func ParseUserTokenClaims(claims jwt.MapClaims) (*UserTokenClaims, error) {
+	userIDFloat, okID := claims["user_id"].(float64)
+	tokenVersionFloat, okVer := claims["token_version"].(float64)
+	sessionIDFloat, okSession := claims["session_id"].(float64)
+	if !okID || !okVer || !okSession {
+		return nil, custom_error.ErrTokenPayloadInvalid
+	}
+
+	parsed := &UserTokenClaims{
+		UserID:       uint(userIDFloat),
+		TokenVersion: int(tokenVersionFloat),
+		SessionID:    uint(sessionIDFloat),
+	}
+	parsed.TokenType, _ = claims["token_type"].(string)
+	parsed.ClientID, _ = claims["client_id"].(string)
+	parsed.TokenID, _ = claims["jti"].(string)
+	parsed.FamilyID, _ = claims["family_id"].(string)
+	if permVersion, ok := claims["perm_version"].(float64); ok {
+		parsed.PermVersion = int(permVersion)
+	}
+	if orgID, ok := claims["org_id"].(float64); ok {
+		parsed.OrganizationID = uint(orgID)
+	}
+
+	// Claim act (RFC 8693) của token giả lập: có mặt nhưng sai định dạng thì từ chối cả token
+	parsed.ActorID = parsed.UserID
+	if raw, exists := claims["act"]; exists {
+		act, isMap := raw.(map[string]interface{})
+		if !isMap {
+			return nil, custom_error.ErrTokenPayloadInvalid
+		}
+		sub, _ := act["sub"].(string)
+		actorID, err := strconv.ParseUint(sub, 10, 64)
+		if err != nil || actorID == 0 {
+			return nil, custom_error.ErrTokenPayloadInvalid
+		}
+		parsed.ActorID = uint(actorID)
+		parsed.Impersonating = true
+	}
+	return parsed, nil
+}
+
+// ValidatedToken là trạng thái hiện tại (đọc từ DB) của token đã qua Validate
+type ValidatedToken struct {
+	User       *models.User
+	Session    *models.Session
+	Role       *models.Role       // nil với Refresh Token
+	Membership *models.Membership // nil khi token không gắn tổ chức
+}
+
+// TokenValidator kiểm tra token user còn được chấp nhận sau khi cấp. RequireAuth và /oauth/introspect
+// dùng chung để hai nơi không lệch nhau khi thêm điều kiện thu hồi mới
+type TokenValidator interface {
+	Validate(ctx context.Context, claims *UserTokenClaims) (*ValidatedToken, error)
+}
+
+type tokenValidator struct {
+	userRepo    repositories.UserRepository
+	sessionRepo repositories.SessionRepository
+	roleRepo    repositories.RoleRepository
+	orgRepo     repositories.OrganizationRepository
+}
+
+func NewTokenValidator(
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	roleRepo repositories.RoleRepository,
+	orgRepo repositories.OrganizationRepository,
+) TokenValidator {
+	return &tokenValidator{userRepo: userRepo, sessionRepo: sessionRepo, roleRepo: roleRepo, orgRepo: orgRepo}
+}
+
+// Validate lần lượt kiểm tra: TokenVersion, đình chỉ, phiên của thiết bị, quyền giả lập của admin,
+// perm_version và membership của tổ chức đang làm việc. Refresh Token chỉ cần là jti mới nhất của phiên
+func (v *tokenValidator) Validate(ctx context.Context, claims *UserTokenClaims) (*ValidatedToken, error) {
+	user, err := v.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil || user.TokenVersion != claims.TokenVersion {
+		return nil, custom_error.ErrUnauthorized
+	}
+	if user.IsSuspended() {
+		return nil, custom_error.ErrAccountSuspended
+	}
+
+	// Token giả lập gắn với phiên của admin (actor), không phải của user đang được giả lập
+	session, err := v.sessionRepo.FindByID(ctx, claims.SessionID)
+	if err != nil || session.UserID != claims.ActorID || session.ClientID != claims.ClientID || !session.IsActive() {
+		return nil, custom_error.ErrSessionRevoked
+	}
+	validated := &ValidatedToken{User: user, Session: session}
+
+	if claims.TokenType == "refresh" {
+		if claims.TokenID != session.RefreshTokenID || claims.FamilyID != session.FamilyID {
+			return nil, custom_error.ErrSessionRevoked
+		}
+		return validated, nil
+	}
+
+	// Admin bị đình chỉ hoặc bị thu hồi quyền giả lập thì token giả lập đang lưu hành cũng mất hiệu lực ngay
+	if claims.Impersonating && !v.canImpersonate(ctx, claims.ActorID) {
+		return nil, custom_error.ErrImpersonationEnded
+	}
+
+	// Quyền của role đã đổi sau khi token được cấp: client phải refresh để nhận danh sách quyền mới
+	role, err := v.roleRepo.FindByName(ctx, user.Role)
+	if err != nil {
+		return nil, custom_error.ErrForbidden
+	}
+	if claims.PermVersion != role.PermissionVersion {
+		return nil, custom_error.ErrPermissionsChanged
+	}
+	validated.Role = role
+
+	if claims.OrganizationID != 0 {
+		membership, err := v.orgRepo.FindMembership(ctx, claims.OrganizationID, user.ID)
+		if err != nil {
+			return nil, custom_error.ErrOrgMembershipRevoked
+		}
+		validated.Membership = membership
+	}
+	return validated, nil
+}
+
+// canImpersonate kiểm tra admin vẫn tồn tại, không bị đình chỉ và role hiện tại vẫn có quyền giả lập
+func (v *tokenValidator) canImpersonate(ctx context.Context, actorID uint) bool {
+	actor, err := v.userRepo.FindByID(ctx, actorID)
+	if err != nil || actor.IsSuspended() {
+		return false
+	}
+	role, err := v.roleRepo.FindByName(ctx, actor.Role)
+	return err == nil && role.HasPermission(models.PermUsersImpersonate)
+}
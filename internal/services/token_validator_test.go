@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/custom_error"
+)
+
+// newValidatorTestService: user thường (id 1), admin có quyền giả lập (id 2), support không có (id 3), user bị đình chỉ (id 4)
+func newValidatorTestService() TokenValidator {
+	now := time.Now()
+	active := now.Add(time.Hour)
+	users := newFakeUserRepo(
+		&models.User{ID: 1, Role: models.RoleUser, TokenVersion: 1},
+		&models.User{ID: 2, Role: models.RoleAdmin, TokenVersion: 1},
+		&models.User{ID: 3, Role: "support", TokenVersion: 1},
+		&models.User{ID: 4, Role: models.RoleUser, TokenVersion: 1, SuspendedAt: &now},
+	)
+	sessions := newFakeSessionRepo(
+		&models.Session{ID: 10, UserID: 1, FamilyID: "f10", RefreshTokenID: "jti-10", ExpiresAt: active},
+		&models.Session{ID: 11, UserID: 1, ExpiresAt: active, RevokedAt: &now},
+		&models.Session{ID: 12, UserID: 1, ClientID: "app", ExpiresAt: active},
+		&models.Session{ID: 20, UserID: 2, ExpiresAt: active},
+		&models.Session{ID: 30, UserID: 3, ExpiresAt: active},
+		&models.Session{ID: 40, UserID: 4, ExpiresAt: active},
+	)
+	userRole := roleWith(models.RoleUser)
+	userRole.PermissionVersion = 2
+	roles := newFakeRoleRepo(userRole, roleWith(models.RoleAdmin, models.PermUsersImpersonate), roleWith("support", models.PermUsersRead))
+	orgs := newFakeOrganizationRepo(&models.Membership{OrganizationID: 5, UserID: 1, Role: models.OrgRoleMember})
+	return NewTokenValidator(users, sessions, roles, orgs)
+}
+
+// accessClaims là Access Token hợp lệ của user 1, từng case chỉ sửa điều kiện đang kiểm tra
+func accessClaims(modify func(claims *UserTokenClaims)) *UserTokenClaims {
+	claims := &UserTokenClaims{TokenType: "access", UserID: 1, ActorID: 1, TokenVersion: 1, SessionID: 10, PermVersion: 2}
+	if modify != nil {
+		modify(claims)
+	}
+	return claims
+}
+
+func TestTokenValidatorAccepts(t *testing.T) {
+	validator := newValidatorTestService()
+	validated, err := validator.Validate(context.Background(), accessClaims(func(c *UserTokenClaims) { c.OrganizationID = 5 }))
+	if err != nil {
+		t.Fatalf("token hợp lệ bị từ chối: %v", err)
+	}
+	if validated.Role == nil || validated.Membership == nil || validated.Session.ID != 10 {
+		t.Fatalf("thiếu trạng thái đã kiểm tra: %+v", validated)
+	}
+
+	refresh := &UserTokenClaims{TokenType: "refresh", UserID: 1, ActorID: 1, TokenVersion: 1, SessionID: 10, TokenID: "jti-10", FamilyID: "f10"}
+	if _, err := validator.Validate(context.Background(), refresh); err != nil {
+		t.Fatalf("refresh token mới nhất bị từ chối: %v", err)
+	}
+
+	// admin giả lập user 1 trên phiên của chính admin
+	impersonated := accessClaims(func(c *UserTokenClaims) { c.ActorID, c.SessionID, c.Impersonating = 2, 20, true })
+	if _, err := validator.Validate(context.Background(), impersonated); err != nil {
+		t.Fatalf("token giả lập hợp lệ bị từ chối: %v", err)
+	}
+}
+
+func TestTokenValidatorRejects(t *testing.T) {
+	cases := []struct {
+		name   string
+		claims *UserTokenClaims
+		want   error
+	}{
+		{"user không tồn tại", accessClaims(func(c *UserTokenClaims) { c.UserID, c.ActorID = 99, 99 }), custom_error.ErrUnauthorized},
+		{"TokenVersion đã tăng (đổi mật khẩu)", accessClaims(func(c *UserTokenClaims) { c.TokenVersion = 0 }), custom_error.ErrUnauthorized},
+		{"tài khoản bị đình chỉ", accessClaims(func(c *UserTokenClaims) { c.UserID, c.ActorID, c.SessionID = 4, 4, 40 }), custom_error.ErrAccountSuspended},
+		{"phiên không tồn tại", accessClaims(func(c *UserTokenClaims) { c.SessionID = 999 }), custom_error.ErrSessionRevoked},
+		{"phiên đã đăng xuất", accessClaims(func(c *UserTokenClaims) { c.SessionID = 11 }), custom_error.ErrSessionRevoked},
+		{"phiên của user khác", accessClaims(func(c *UserTokenClaims) { c.SessionID = 20 }), custom_error.ErrSessionRevoked},
+		{"phiên của OAuth client khác", accessClaims(func(c *UserTokenClaims) { c.SessionID = 12 }), custom_error.ErrSessionRevoked},
+		{"refresh token đã bị xoay vòng", &UserTokenClaims{TokenType: "refresh", UserID: 1, ActorID: 1, TokenVersion: 1, SessionID: 10, TokenID: "jti-old", FamilyID: "f10"}, custom_error.ErrSessionRevoked},
+		{"refresh token khác họ", &UserTokenClaims{TokenType: "refresh", UserID: 1, ActorID: 1, TokenVersion: 1, SessionID: 10, TokenID: "jti-10", FamilyID: "other"}, custom_error.ErrSessionRevoked},
+		{"admin mất quyền giả lập", accessClaims(func(c *UserTokenClaims) { c.ActorID, c.SessionID, c.Impersonating = 3, 30, true }), custom_error.ErrImpersonationEnded},
+		{"quyền của role đã đổi", accessClaims(func(c *UserTokenClaims) { c.PermVersion = 1 }), custom_error.ErrPermissionsChanged},
+		{"đã bị gỡ khỏi tổ chức", accessClaims(func(c *UserTokenClaims) { c.OrganizationID = 6 }), custom_error.ErrOrgMembershipRevoked},
+	}
+
+	validator := newValidatorTestService()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := validator.Validate(context.Background(), tc.claims); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, muốn %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokenValidatorRejectsMissingRole(t *testing.T) {
+	users := newFakeUserRepo(&models.User{ID: 1, Role: "deleted-role", TokenVersion: 1})
+	sessions := newFakeSessionRepo(&models.Session{ID: 10, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	validator := NewTokenValidator(users, sessions, newFakeRoleRepo(), newFakeOrganizationRepo())
+
+	if _, err := validator.Validate(context.Background(), accessClaims(nil)); !errors.Is(err, custom_error.ErrForbidden) {
+		t.Fatalf("err = %v, muốn ErrForbidden", err)
+	}
+}
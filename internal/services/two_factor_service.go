@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-core-api/internal/models"
+	"go-core-api/internal/repositories"
+	"go-core-api/pkg/config"
+	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/utils"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+// TwoFactorEnrollment chứa dữ liệu để user thêm tài khoản vào ứng dụng Authenticator
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"` // Frontend render chuỗi này thành mã QR
+}
+
+type TwoFactorService interface {
+	Enroll(ctx context.Context, userID uint) (*TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error)
+	VerifyCode(ctx context.Context, user *models.User, code string) error
+}
+
+type twoFactorService struct {
+	repo         repositories.UserRepository
+	recoveryRepo repositories.RecoveryCodeRepository
+}
+
+func NewTwoFactorService(repo repositories.UserRepository, recoveryRepo repositories.RecoveryCodeRepository) TwoFactorService {
+	return &twoFactorService{
+		repo:         repo,
+		recoveryRepo: recoveryRepo,
+	}
+}
+
+// Enroll sinh secret mới (chưa có hiệu lực cho tới khi user xác nhận bằng một mã hợp lệ)
+func (s *twoFactorService) Enroll(ctx context.Context, userID uint) (*TwoFactorEnrollment, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return nil, custom_error.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	user.TwoFactorSecret = &secret
+	user.TwoFactorLastStep = 0
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	issuer := config.AppConfig.Auth.TOTPIssuer
+	if issuer == "" {
+		issuer = "GoCoreAPI"
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: utils.BuildOTPAuthURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm bật 2FA sau khi user nhập đúng mã đầu tiên, trả về bộ recovery code (chỉ hiển thị 1 lần)
+func (s *twoFactorService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return nil, custom_error.ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == nil {
+		return nil, custom_error.ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := utils.ValidateTOTP(*user.TwoFactorSecret, code, time.Now())
+	if !ok {
+		return nil, custom_error.ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	user.TwoFactorEnabledAt = &now
+	user.TwoFactorLastStep = step
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// Disable tắt 2FA, yêu cầu mã TOTP hoặc recovery code hợp lệ
+func (s *twoFactorService) Disable(ctx context.Context, userID uint, code string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return custom_error.ErrUserNotFound
+	}
+	if user.TwoFactorEnabledAt == nil {
+		return custom_error.ErrTwoFactorNotEnabled
+	}
+
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	user.TwoFactorSecret = nil
+	user.TwoFactorEnabledAt = nil
+	user.TwoFactorLastStep = 0
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+
+	if err := s.recoveryRepo.DeleteByUser(ctx, user.ID); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes huỷ bộ mã cũ và cấp bộ mã mới
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+	if user.TwoFactorEnabledAt == nil {
+		return nil, custom_error.ErrTwoFactorNotEnabled
+	}
+
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// VerifyCode chấp nhận mã TOTP 6 số hoặc một recovery code chưa dùng
+func (s *twoFactorService) VerifyCode(ctx context.Context, user *models.User, code string) error {
+	if user.TwoFactorEnabledAt == nil || user.TwoFactorSecret == nil {
+		return custom_error.ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == utils.TOTPDigits {
+		step, ok := utils.ValidateTOTP(*user.TwoFactorSecret, code, time.Now())
+		if !ok {
+			return custom_error.ErrInvalidTwoFactorCode
+		}
+
+		// Mỗi mã chỉ dùng được một lần: điều kiện "step mới hơn lần dùng trước" nằm trong câu UPDATE
+		// nên hai request song song gửi cùng một mã chỉ một request thành công
+		claimed, err := s.repo.ClaimTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return custom_error.ErrInternalServer
+		}
+		if !claimed {
+			return custom_error.ErrInvalidTwoFactorCode
+		}
+		user.TwoFactorLastStep = step
+		return nil
+	}
+
+	return s.useRecoveryCode(ctx, user.ID, code)
+}
+
+func (s *twoFactorService) useRecoveryCode(ctx context.Context, userID uint, code string) error {
+	normalized := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	recovery, err := s.recoveryRepo.FindUnused(ctx, userID, utils.HashToken(normalized))
+	if err != nil {
+		return custom_error.ErrInvalidTwoFactorCode
+	}
+
+	used, err := s.recoveryRepo.MarkUsed(ctx, recovery.ID)
+	if err != nil {
+		return custom_error.ErrInternalServer
+	}
+	if !used {
+		return custom_error.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// issueRecoveryCodes sinh mã dạng "xxxxx-xxxxx", chỉ lưu bản băm vào DB
+func (s *twoFactorService) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	plainCodes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.GenerateRandomString(10, recoveryCodeAlphabet)
+		if err != nil {
+			return nil, custom_error.ErrInternalServer
+		}
+		plainCodes = append(plainCodes, raw[:5]+"-"+raw[5:])
+		records = append(records, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(raw),
+		})
+	}
+
+	if err := s.recoveryRepo.ReplaceForUser(ctx, userID, records); err != nil {
+		return nil, custom_error.ErrInternalServer
+	}
+	return plainCodes, nil
+}
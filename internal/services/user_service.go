@@ -4,13 +4,14 @@ import (
 	"context"
 	"math"
 	"os"
+	"time"
 
 	"go-core-api/internal/models"
 	"go-core-api/internal/repositories"
+	"go-core-api/pkg/config"
 	"go-core-api/pkg/custom_error"
+	"go-core-api/pkg/hasher"
 	"go-core-api/pkg/utils"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
@@ -19,17 +20,30 @@ type UserService interface {
 	GetProfile(ctx context.Context, userID uint) (*models.User, error)
 	UpdateProfile(ctx context.Context, userID uint, fullName, avatar, phone string) error
 	GetUserByID(ctx context.Context, id uint) (*models.User, error)
-	AdminUpdateUser(ctx context.Context, id uint, role string) error
-	DeleteUser(ctx context.Context, id uint) error
-	PurgeUser(ctx context.Context, id uint) error
+	AdminUpdateUser(ctx context.Context, actorID, id uint, role string) error
+	DeleteUser(ctx context.Context, actorID, id uint) error
+	PurgeUser(ctx context.Context, actorID, id uint) error
+	UnlockUser(ctx context.Context, id uint) error
+	SuspendUser(ctx context.Context, actorID, id uint, reason string, until *time.Time) error
+	UnsuspendUser(ctx context.Context, actorID, id uint) error
 }
 
 type userService struct {
-	repo repositories.UserRepository
+	repo         repositories.UserRepository
+	throttleRepo repositories.LoginThrottleRepository
+	roleRepo     repositories.RoleRepository
+	hasher       hasher.PasswordHasher
+	policy       PasswordPolicy
 }
 
-func NewUserService(repo repositories.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(repo repositories.UserRepository, throttleRepo repositories.LoginThrottleRepository, roleRepo repositories.RoleRepository, passwordHasher hasher.PasswordHasher, policy PasswordPolicy) UserService {
+	return &userService{
+		repo:         repo,
+		throttleRepo: throttleRepo,
+		roleRepo:     roleRepo,
+		hasher:       passwordHasher,
+		policy:       policy,
+	}
 }
 
 // GetListUsers xử lý logic tính toán tổng số trang
@@ -53,21 +67,30 @@ func (s *userService) ChangePassword(ctx context.Context, userID uint, oldPasswo
 	}
 
 	// 2. Kiểm tra mật khẩu cũ xem có khớp không
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
-	if err != nil {
+	match, _, err := s.hasher.Verify(oldPassword, user.Password)
+	if err != nil || !match {
 		return custom_error.ErrWrongPassword
 	}
 
-	// 3. Mã hoá mật khẩu mới
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	// 3. Mật khẩu mới phải qua chính sách mật khẩu (độ mạnh, không trùng email, không dùng lại, chưa bị lộ)
+	if err := s.policy.Validate(ctx, "new_password", newPassword, user); err != nil {
+		return err
+	}
+
+	// 4. Mã hoá mật khẩu mới
+	hashedPassword, err := s.hasher.Hash(newPassword)
 	if err != nil {
 		return custom_error.ErrInternalServer
 	}
 
-	// 4. Lưu vào database
-	user.Password = string(hashedPassword)
+	// 5. Lưu vào database
+	user.Password = hashedPassword
 	user.TokenVersion += 1
-	return s.repo.Update(ctx, user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.policy.Remember(ctx, user.ID, hashedPassword)
+	return nil
 }
 
 // GetProfile lấy thông tin chi tiết của 1 user
@@ -91,7 +114,17 @@ func (s *userService) UpdateProfile(ctx context.Context, userID uint, fullName,
 		user.FullName = fullName
 	}
 	if phone != "" {
-		user.Phone = phone
+		normalized, err := utils.NormalizePhone(phone, config.AppConfig.SMS.DefaultCountryCode)
+		if err != nil {
+			return err
+		}
+		// Đổi số thì phải xác thực lại, mã OTP gửi tới số cũ cũng hết hiệu lực
+		if normalized != user.Phone {
+			user.Phone = normalized
+			user.PhoneVerifiedAt = nil
+			user.PhoneOTPHash = nil
+			user.PhoneOTPExpires = nil
+		}
 	}
 
 	// [REFACTOR] Xoá file ảnh cũ khỏi hệ thống vật lý trước khi lưu ảnh mới
@@ -119,15 +152,33 @@ func (s *userService) GetUserByID(ctx context.Context, id uint) (*models.User, e
 }
 
 // Cập nhật thông tin User
-func (s *userService) AdminUpdateUser(ctx context.Context, id uint, role string) error {
+func (s *userService) AdminUpdateUser(ctx context.Context, actorID, id uint, role string) error {
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return custom_error.ErrUserNotFound
 	}
 
-	// Validate role
-	if role != models.RoleAdmin && role != models.RoleUser {
-		return custom_error.New(400, "ERR_INVALID_ROLE", "Quyền không hợp lệ")
+	// Role phải tồn tại trong bảng roles
+	newRole, err := s.roleRepo.FindByName(ctx, role)
+	if err != nil {
+		return custom_error.ErrInvalidRole
+	}
+
+	// Chống leo thang đặc quyền: chỉ được gán (hoặc gỡ) role không vượt quá quyền của chính mình
+	actor, err := s.repo.FindByID(ctx, actorID)
+	if err != nil {
+		return custom_error.ErrUnauthorized
+	}
+	actorRole, err := s.roleRepo.FindByName(ctx, actor.Role)
+	if err != nil {
+		return custom_error.ErrForbidden
+	}
+	currentRole, err := s.roleRepo.FindByName(ctx, user.Role)
+	if err == nil && !coversPermissions(actorRole, currentRole) {
+		return custom_error.ErrForbidden
+	}
+	if !coversPermissions(actorRole, newRole) {
+		return custom_error.ErrForbidden
 	}
 
 	user.Role = role
@@ -135,20 +186,101 @@ func (s *userService) AdminUpdateUser(ctx context.Context, id uint, role string)
 	return s.repo.Update(ctx, user)
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id uint) error {
-	_, err := s.repo.FindByID(ctx, id)
+// SuspendUser đình chỉ tài khoản: mọi lượt đăng nhập, làm mới token và request đều bị từ chối
+// với ERR_ACCOUNT_SUSPENDED. until = nil nghĩa là vô thời hạn, ngược lại tự động gỡ khi tới hạn.
+// Khác DeleteUser, tài khoản vẫn giữ email nên không ai đăng ký lại được email đó
+func (s *userService) SuspendUser(ctx context.Context, actorID, id uint, reason string, until *time.Time) error {
+	if actorID == id {
+		return custom_error.ErrCannotSuspendSelf
+	}
+	if until != nil && !until.After(time.Now()) {
+		return custom_error.ErrInvalidSuspensionEnd
+	}
+
+	user, err := s.findManageableUser(ctx, actorID, id)
 	if err != nil {
-		return custom_error.ErrUserNotFound
+		return err
+	}
+
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspendedUntil = until
+	user.SuspendedBy = &actorID
+	user.SuspensionReason = reason
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// UnsuspendUser gỡ đình chỉ trước hạn. Các phiên đăng nhập cũ dùng lại được ngay
+func (s *userService) UnsuspendUser(ctx context.Context, actorID, id uint) error {
+	user, err := s.findManageableUser(ctx, actorID, id)
+	if err != nil {
+		return err
+	}
+	if !user.IsSuspended() {
+		return custom_error.ErrUserNotSuspended
+	}
+
+	user.SuspendedAt = nil
+	user.SuspendedUntil = nil
+	user.SuspendedBy = nil
+	user.SuspensionReason = ""
+	if err := s.repo.Update(ctx, user); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
+
+// findManageableUser tìm user đích, chỉ cho phép khi quyền của người thao tác bao trùm role của user đó
+// (support không thể đình chỉ admin)
+func (s *userService) findManageableUser(ctx context.Context, actorID, id uint) (*models.User, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, custom_error.ErrUserNotFound
+	}
+
+	actor, err := s.repo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, custom_error.ErrUnauthorized
+	}
+	actorRole, err := s.roleRepo.FindByName(ctx, actor.Role)
+	if err != nil {
+		return nil, custom_error.ErrForbidden
+	}
+	userRole, err := s.roleRepo.FindByName(ctx, user.Role)
+	if err == nil && !coversPermissions(actorRole, userRole) {
+		return nil, custom_error.ErrForbidden
+	}
+	return user, nil
+}
+
+// coversPermissions kiểm tra role của người thao tác có mọi quyền của role đích
+func coversPermissions(actor, target *models.Role) bool {
+	for _, permission := range target.Permissions {
+		if !actor.HasPermission(permission.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteUser và PurgeUser chỉ áp dụng cho user có role không vượt quá quyền của người thao tác,
+// tránh role tuỳ biến có users:delete/users:purge xoá được admin
+func (s *userService) DeleteUser(ctx context.Context, actorID, id uint) error {
+	if _, err := s.findManageableUser(ctx, actorID, id); err != nil {
+		return err
 	}
 
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *userService) PurgeUser(ctx context.Context, id uint) error {
+func (s *userService) PurgeUser(ctx context.Context, actorID, id uint) error {
 	// 1. Lấy thông tin user trước khi xoá để lấy đường dẫn Avatar
-	user, err := s.repo.FindByID(ctx, id)
+	user, err := s.findManageableUser(ctx, actorID, id)
 	if err != nil {
-		return custom_error.ErrUserNotFound
+		return err
 	}
 
 	// 2. Xoá cứng trong Database
@@ -170,3 +302,15 @@ func (s *userService) PurgeUser(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+// UnlockUser gỡ khoá đăng nhập và xoá mọi bộ đếm sai mật khẩu của user
+func (s *userService) UnlockUser(ctx context.Context, id uint) error {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return custom_error.ErrUserNotFound
+	}
+
+	if err := s.throttleRepo.ResetByUser(ctx, id); err != nil {
+		return custom_error.ErrInternalServer
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-core-api/internal/models"
+	"go-core-api/pkg/custom_error"
+)
+
+// newManageTestService tạo service với 3 user: admin (id 1), support chỉ có quyền xoá (id 2), user thường (id 3)
+func newManageTestService() (UserService, *fakeUserRepo) {
+	users := newFakeUserRepo(
+		&models.User{ID: 1, Email: "admin@example.com", Role: models.RoleAdmin},
+		&models.User{ID: 2, Email: "support@example.com", Role: "support"},
+		&models.User{ID: 3, Email: "user@example.com", Role: models.RoleUser},
+	)
+	roles := newFakeRoleRepo(
+		roleWith(models.RoleAdmin, models.PermUsersRead, models.PermUsersDelete, models.PermUsersPurge, models.PermUsersImpersonate),
+		roleWith("support", models.PermUsersDelete, models.PermUsersPurge),
+		roleWith(models.RoleUser),
+	)
+	return NewUserService(users, nil, roles, nil, nil), users
+}
+
+func TestDeleteAndPurgeRejectStrongerTarget(t *testing.T) {
+	ctx := context.Background()
+
+	actions := map[string]func(s UserService, actorID, id uint) error{
+		"DeleteUser": func(s UserService, actorID, id uint) error { return s.DeleteUser(ctx, actorID, id) },
+		"PurgeUser":  func(s UserService, actorID, id uint) error { return s.PurgeUser(ctx, actorID, id) },
+	}
+	for name, action := range actions {
+		t.Run(name, func(t *testing.T) {
+			service, users := newManageTestService()
+
+			// support có users:delete/users:purge nhưng không có mọi quyền của admin
+			if err := action(service, 2, 1); !errors.Is(err, custom_error.ErrForbidden) {
+				t.Fatalf("support xoá admin: err = %v, muốn ErrForbidden", err)
+			}
+			if _, ok := users.users[1]; !ok {
+				t.Fatal("admin đã bị xoá dù bị từ chối")
+			}
+
+			if err := action(service, 2, 3); err != nil {
+				t.Fatalf("support xoá user thường: %v", err)
+			}
+			if _, ok := users.users[3]; ok {
+				t.Fatal("user thường chưa bị xoá")
+			}
+		})
+	}
+}
+
+func TestDeleteUserNotFound(t *testing.T) {
+	service, _ := newManageTestService()
+	if err := service.DeleteUser(context.Background(), 1, 99); !errors.Is(err, custom_error.ErrUserNotFound) {
+		t.Fatalf("err = %v, muốn ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package breached kiểm tra mật khẩu có nằm trong danh sách mật khẩu đã bị lộ hay không.
+// Danh sách được lưu theo kiểu k-anonymity giống Have I Been Pwned: SHA-1 của mật khẩu
+// được chia thành 5 ký tự tiền tố + 35 ký tự còn lại, tra cứu hoàn toàn ở local
+package breached
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	prefixLength = 5
+	hashLength   = 40
+)
+
+//go:embed common_passwords.txt
+var bundledList string
+
+// Checker tra cứu mật khẩu trong danh sách đã bị lộ
+type Checker interface {
+	IsBreached(password string) bool
+}
+
+// rangeChecker nhóm các hash theo tiền tố 5 ký tự, đúng như một "range" của HIBP
+type rangeChecker struct {
+	ranges map[string]map[string]struct{}
+}
+
+// New nạp danh sách đi kèm mã nguồn, cộng thêm file cấu hình (nếu có).
+// Mỗi dòng của file có dạng "SHA1" hoặc "SHA1:COUNT" (file ordered-by-hash của HIBP),
+// dòng trống và dòng bắt đầu bằng "#" bị bỏ qua
+func New(extraFile string) (Checker, error) {
+	c := &rangeChecker{ranges: make(map[string]map[string]struct{})}
+	if err := c.load(strings.NewReader(bundledList)); err != nil {
+		return nil, err
+	}
+
+	if extraFile != "" {
+		f, err := os.Open(extraFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if err := c.load(f); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *rangeChecker) load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != hashLength {
+			continue // Bỏ qua dòng sai định dạng thay vì làm hỏng cả danh sách
+		}
+
+		prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+		if c.ranges[prefix] == nil {
+			c.ranges[prefix] = make(map[string]struct{})
+		}
+		c.ranges[prefix][suffix] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+func (c *rangeChecker) IsBreached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, ok := c.ranges[hash[:prefixLength]]
+	if !ok {
+		return false
+	}
+	_, found := suffixes[hash[prefixLength:]]
+	return found
+}
@@ -0,0 +1,59 @@
+// Package cache cung cấp kho key-value có thời hạn (TTL) dùng để giảm tải cho database:
+// bộ nhớ trong tiến trình (LRU) cho một instance, hoặc Redis (giao thức RESP) khi chạy nhiều instance
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Các driver được hỗ trợ
+const (
+	DriverNone   = "none"
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// Store lưu giá trị dạng byte theo key, hết hạn sau ttl.
+// Lỗi của Store không nên làm hỏng request: nơi gọi chỉ ghi log và đọc thẳng từ database
+type Store interface {
+	// Get trả về found = false khi key không tồn tại hoặc đã hết hạn
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Config là cấu hình cache (mục "cache" trong file config)
+type Config struct {
+	Driver     string      `mapstructure:"driver"`
+	TTLSeconds int         `mapstructure:"ttl_seconds"`
+	MaxEntries int         `mapstructure:"max_entries"` // Chỉ dùng cho driver memory
+	Redis      RedisConfig `mapstructure:"redis"`
+}
+
+// TTL trả về thời gian sống của mỗi entry, mặc định 60 giây
+func (c Config) TTL() time.Duration {
+	if c.TTLSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// New khởi tạo Store theo cấu hình. Trả về nil (không lỗi) khi cache bị tắt
+func New(cfg Config) (Store, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DriverNone:
+		return nil, nil
+	case DriverMemory:
+		return NewMemoryStore(cfg.MaxEntries), nil
+	case DriverRedis:
+		if cfg.Redis.Addr == "" {
+			return nil, errors.New("cache.redis.addr không được bỏ trống khi dùng driver redis")
+		}
+		return NewRedisStore(cfg.Redis), nil
+	default:
+		return nil, errors.New("driver cache không được hỗ trợ: " + cfg.Driver)
+	}
+}
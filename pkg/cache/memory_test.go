@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	if _, found, err := store.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("Get key chưa có: found = %v, err = %v", found, err)
+	}
+
+	if err := store.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, found, err := store.Get(ctx, "a")
+	if err != nil || !found || string(value) != "1" {
+		t.Fatalf("Get a = %q, %v, %v", value, found, err)
+	}
+
+	if err := store.Delete(ctx, "a", "missing"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, found, _ := store.Get(ctx, "a"); found {
+		t.Fatal("key a vẫn còn sau khi Delete")
+	}
+}
+
+func TestMemoryStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	if err := store.Set(ctx, "short", []byte("x"), 20*time.Millisecond); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := store.Set(ctx, "long", []byte("y"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, found, _ := store.Get(ctx, "short"); found {
+		t.Fatal("key short phải hết hạn")
+	}
+	if _, found, _ := store.Get(ctx, "long"); !found {
+		t.Fatal("key long chưa hết hạn nhưng không đọc được")
+	}
+
+	// Entry hết hạn được dọn khi đọc, không chiếm chỗ trong LRU
+	if n := store.(*memoryStore).order.Len(); n != 1 {
+		t.Fatalf("số entry còn lại = %d, muốn 1", n)
+	}
+}
+
+func TestMemoryStoreSetRefreshesTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	_ = store.Set(ctx, "k", []byte("old"), 20*time.Millisecond)
+	_ = store.Set(ctx, "k", []byte("new"), time.Minute)
+	time.Sleep(40 * time.Millisecond)
+
+	value, found, _ := store.Get(ctx, "k")
+	if !found || string(value) != "new" {
+		t.Fatalf("Get k = %q, %v; muốn giá trị mới với TTL mới", value, found)
+	}
+}
+
+func TestMemoryStoreLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	_ = store.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = store.Set(ctx, "b", []byte("2"), time.Minute)
+
+	// Đọc a để a thành entry vừa dùng, b trở thành entry lâu nhất
+	if _, found, _ := store.Get(ctx, "a"); !found {
+		t.Fatal("không đọc được a")
+	}
+	_ = store.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, found, _ := store.Get(ctx, "b"); found {
+		t.Fatal("b phải bị loại khi cache đầy")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found, _ := store.Get(ctx, key); !found {
+			t.Fatalf("%s không được phép bị loại", key)
+		}
+	}
+}
+
+func TestNewMemoryStoreDefaultSize(t *testing.T) {
+	store := NewMemoryStore(0).(*memoryStore)
+	if store.maxEntries != defaultMaxEntries {
+		t.Fatalf("maxEntries = %d, muốn %d", store.maxEntries, defaultMaxEntries)
+	}
+}
@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisConfig là cấu hình kết nối tới Redis (hoặc server tương thích giao thức RESP như KeyDB, Valkey)
+type RedisConfig struct {
+	Addr      string `mapstructure:"addr"` // host:port
+	Password  string `mapstructure:"password"`
+	DB        int    `mapstructure:"db"`
+	KeyPrefix string `mapstructure:"key_prefix"` // Tách key khi nhiều ứng dụng dùng chung một Redis
+	PoolSize  int    `mapstructure:"pool_size"`  // Số kết nối rảnh giữ lại để tái sử dụng, mặc định 10
+}
+
+const redisTimeout = 2 * time.Second
+
+// redisError là lỗi do server trả về (dòng "-ERR ..."), kết nối vẫn dùng tiếp được
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisStore là client RESP tối giản (chỉ GET/SET/DEL), không phụ thuộc thư viện bên ngoài
+type redisStore struct {
+	cfg  RedisConfig
+	idle chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisStore khởi tạo client, kết nối được mở khi có lệnh đầu tiên
+func NewRedisStore(cfg RedisConfig) Store {
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 10
+	}
+	return &redisStore{cfg: cfg, idle: make(chan *redisConn, cfg.PoolSize)}
+}
+
+func (r *redisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.cfg.KeyPrefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: GET trả về kiểu không mong đợi %T", reply)
+	}
+	return value, true, nil
+}
+
+func (r *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := r.do(ctx, "SET", r.cfg.KeyPrefix+key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (r *redisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.cfg.KeyPrefix+key)
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// do gửi một lệnh và đọc phản hồi. Kết nối lỗi mạng/giao thức bị đóng, không trả lại pool
+func (r *redisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.roundTrip(ctx, args)
+	var serverErr redisError
+	if err != nil && !errors.As(err, &serverErr) {
+		c.conn.Close()
+		return nil, err
+	}
+
+	r.release(c)
+	return reply, err
+}
+
+func (r *redisStore) acquire(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	if r.cfg.Password != "" {
+		if _, err := c.roundTrip(ctx, []string{"AUTH", r.cfg.Password}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.cfg.DB != 0 {
+		if _, err := c.roundTrip(ctx, []string{"SELECT", strconv.Itoa(r.cfg.DB)}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (r *redisStore) release(c *redisConn) {
+	select {
+	case r.idle <- c:
+	default:
+		c.conn.Close() // Pool đã đầy
+	}
+}
+
+func (c *redisConn) roundTrip(ctx context.Context, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// Lệnh được gửi dạng mảng các bulk string: *<n>\r\n$<len>\r\n<arg>\r\n...
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// readReply đọc một phản hồi RESP: +simple, -error, :integer, $bulk ($-1 = nil), *array
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: phản hồi rỗng")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("redis: độ dài bulk string không hợp lệ: %w", err)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2) // Kèm \r\n ở cuối
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("redis: độ dài mảng không hợp lệ: %w", err)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: kiểu phản hồi không hỗ trợ %q", line[0])
+	}
+}
+
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: dòng phản hồi không kết thúc bằng CRLF")
+	}
+	return line[:len(line)-2], nil
+}
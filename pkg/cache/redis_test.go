@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis là server RESP chạy trong tiến trình, hỗ trợ đủ lệnh mà redisStore dùng
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]fakeRedisEntry
+	commands [][]string
+	conns    int
+}
+
+type fakeRedisEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("không mở được listener: %v", err)
+	}
+	server := &fakeRedis{listener: listener, password: password, data: make(map[string]fakeRedisEntry)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.conns++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authed := s.password == ""
+
+	for {
+		// Lệnh từ client có cùng định dạng mảng bulk string nên đọc lại được bằng readReply
+		reply, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			b, _ := item.([]byte)
+			args[i] = string(b)
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		var out string
+		switch {
+		case len(args) == 0:
+			out = "-ERR empty command\r\n"
+		case strings.EqualFold(args[0], "AUTH"):
+			if len(args) == 2 && args[1] == s.password {
+				authed = true
+				out = "+OK\r\n"
+			} else {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		default:
+			out = s.exec(args)
+		}
+		s.mu.Unlock()
+
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		entry, ok := s.data[args[1]]
+		if !ok || (!entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)) {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(entry.value), entry.value)
+	case "SET":
+		entry := fakeRedisEntry{value: args[2]}
+		if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+			ms, err := strconv.Atoi(args[4])
+			if err != nil || ms <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			entry.expiresAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		s.data[args[1]] = entry
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+func (s *fakeRedis) lastCommand() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands[len(s.commands)-1]
+}
+
+func TestRedisStoreGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(RedisConfig{Addr: server.addr(), KeyPrefix: "app:"})
+
+	if _, found, err := store.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("Get key chưa có: found = %v, err = %v", found, err)
+	}
+
+	// Giá trị nhị phân (gob) có thể chứa \r\n, phải đi qua bulk string nguyên vẹn
+	value := []byte("line1\r\nline2\x00")
+	if err := store.Set(ctx, "user:1", value, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if cmd := server.lastCommand(); cmd[1] != "app:user:1" || cmd[3] != "PX" || cmd[4] != "60000" {
+		t.Fatalf("lệnh SET = %q, muốn key có prefix và TTL tính bằng ms", cmd)
+	}
+
+	got, found, err := store.Get(ctx, "user:1")
+	if err != nil || !found || string(got) != string(value) {
+		t.Fatalf("Get = %q, %v, %v", got, found, err)
+	}
+
+	if err := store.Delete(ctx, "user:1", "user:2"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if cmd := server.lastCommand(); len(cmd) != 3 || cmd[0] != "DEL" {
+		t.Fatalf("lệnh DEL = %q, muốn xoá nhiều key trong một lệnh", cmd)
+	}
+	if _, found, _ := store.Get(ctx, "user:1"); found {
+		t.Fatal("key vẫn còn sau khi Delete")
+	}
+}
+
+func TestRedisStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(RedisConfig{Addr: server.addr()})
+
+	if err := store.Set(ctx, "k", []byte("v"), 20*time.Millisecond); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, found, _ := store.Get(ctx, "k"); found {
+		t.Fatal("key phải hết hạn")
+	}
+}
+
+func TestRedisStoreAuthAndSelect(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "secret")
+
+	store := NewRedisStore(RedisConfig{Addr: server.addr(), Password: "secret", DB: 2})
+	if err := store.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	server.mu.Lock()
+	first, second := server.commands[0], server.commands[1]
+	server.mu.Unlock()
+	if first[0] != "AUTH" || second[0] != "SELECT" || second[1] != "2" {
+		t.Fatalf("kết nối mới phải gửi AUTH rồi SELECT, nhận %q, %q", first, second)
+	}
+
+	wrong := NewRedisStore(RedisConfig{Addr: server.addr(), Password: "wrong"})
+	var serverErr redisError
+	if err := wrong.Set(ctx, "k", []byte("v"), time.Minute); !errors.As(err, &serverErr) {
+		t.Fatalf("sai mật khẩu phải trả lỗi từ server, nhận %v", err)
+	}
+}
+
+func TestRedisStoreReusesConnections(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(RedisConfig{Addr: server.addr()})
+
+	for i := 0; i < 5; i++ {
+		if err := store.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	server.mu.Lock()
+	conns := server.conns
+	server.mu.Unlock()
+	if conns != 1 {
+		t.Fatalf("mở %d kết nối, muốn tái sử dụng 1 kết nối từ pool", conns)
+	}
+}
+
+func TestRedisStoreServerErrorKeepsConnection(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(RedisConfig{Addr: server.addr()})
+
+	// TTL 0 bị server từ chối: lỗi "-ERR" không làm hỏng kết nối
+	var serverErr redisError
+	if err := store.Set(ctx, "k", []byte("v"), 0); !errors.As(err, &serverErr) {
+		t.Fatalf("muốn lỗi từ server, nhận %v", err)
+	}
+	if err := store.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("Set sau lỗi server: %v", err)
+	}
+	server.mu.Lock()
+	conns := server.conns
+	server.mu.Unlock()
+	if conns != 1 {
+		t.Fatalf("mở %d kết nối, lỗi server không được đóng kết nối", conns)
+	}
+}
+
+func TestRedisStoreUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("không mở được listener: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	store := NewRedisStore(RedisConfig{Addr: addr})
+	if _, _, err := store.Get(context.Background(), "k"); err == nil {
+		t.Fatal("Redis không kết nối được phải trả lỗi")
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	if store, err := New(Config{}); err != nil || store != nil {
+		t.Fatalf("driver rỗng phải tắt cache, nhận %v, %v", store, err)
+	}
+	if _, err := New(Config{Driver: DriverRedis}); err == nil {
+		t.Fatal("driver redis thiếu addr phải báo lỗi")
+	}
+	if _, err := New(Config{Driver: "memcached"}); err == nil {
+		t.Fatal("driver không hỗ trợ phải báo lỗi")
+	}
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"go-core-api/pkg/cache"
+	"go-core-api/pkg/hasher"
+	"go-core-api/pkg/jwtkeys"
 	"go-core-api/pkg/logger"
+	"go-core-api/pkg/oauth"
+	"go-core-api/pkg/sms"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -19,10 +24,46 @@ type Config struct {
 		MaxOpenConns int    `mapstructure:"max_open_conns"`
 	} `mapstructure:"database"`
 	JWT struct {
-		Secret            string `mapstructure:"secret"`
-		AccessExpiration  int    `mapstructure:"access_expiration"`
-		RefreshExpiration int    `mapstructure:"refresh_expiration"`
+		Secret            string              `mapstructure:"secret"`
+		AccessExpiration  int                 `mapstructure:"access_expiration"`
+		RefreshExpiration int                 `mapstructure:"refresh_expiration"`
+		SigningKeyID      string              `mapstructure:"signing_key_id"`
+		Keys              []jwtkeys.KeyConfig `mapstructure:"keys"`
 	} `mapstructure:"jwt"`
+	Auth struct {
+		TOTPIssuer            string `mapstructure:"totp_issuer"`
+		EmailVerificationMode string `mapstructure:"email_verification_mode"`
+		VerifyEmailURL        string `mapstructure:"verify_email_url"`
+		InvitationURL         string `mapstructure:"invitation_url"`
+		OrgInvitationURL      string `mapstructure:"org_invitation_url"`
+		EmailChangeURL        string `mapstructure:"email_change_url"`
+		MagicLinkURL          string `mapstructure:"magic_link_url"`
+		ReauthMaxAgeMinutes   int    `mapstructure:"reauth_max_age_minutes"`
+		Cookie                struct {
+			Enabled  bool   `mapstructure:"enabled"`
+			Domain   string `mapstructure:"domain"`
+			SameSite string `mapstructure:"same_site"`
+		} `mapstructure:"cookie"`
+		Lockout struct {
+			FreeAttempts         int `mapstructure:"free_attempts"`
+			MaxFailures          int `mapstructure:"max_failures"`
+			LockoutMinutes       int `mapstructure:"lockout_minutes"`
+			MaxDelaySeconds      int `mapstructure:"max_delay_seconds"`
+			FailureWindowMinutes int `mapstructure:"failure_window_minutes"`
+		} `mapstructure:"lockout"`
+	} `mapstructure:"auth"`
+	Password       hasher.Config `mapstructure:"password"`
+	PasswordPolicy struct {
+		MinLength        int    `mapstructure:"min_length"`
+		MaxLength        int    `mapstructure:"max_length"`
+		RequireUpper     bool   `mapstructure:"require_upper"`
+		RequireLower     bool   `mapstructure:"require_lower"`
+		RequireDigit     bool   `mapstructure:"require_digit"`
+		RequireSymbol    bool   `mapstructure:"require_symbol"`
+		HistorySize      int    `mapstructure:"history_size"`
+		BreachedListFile string `mapstructure:"breached_list_file"`
+	} `mapstructure:"password_policy"`
+	OAuth  oauth.Config `mapstructure:"oauth"`
 	Mailer struct {
 		Host     string `mapstructure:"host"`
 		Port     int    `mapstructure:"port"`
@@ -30,8 +71,17 @@ type Config struct {
 		Password string `mapstructure:"password"`
 		From     string `mapstructure:"from"`
 	} `mapstructure:"mailer"`
+	SMS   sms.Config   `mapstructure:"sms"`
+	Cache cache.Config `mapstructure:"cache"`
 }
 
+// Các chế độ bắt buộc xác thực email (auth.email_verification_mode)
+const (
+	EmailVerificationOff    = "off"
+	EmailVerificationLogin  = "login"  // Từ chối đăng nhập khi chưa xác thực
+	EmailVerificationRoutes = "routes" // Cho đăng nhập, chỉ chặn các route gắn RequireVerifiedEmail
+)
+
 var AppConfig *Config
 
 func LoadConfig() {
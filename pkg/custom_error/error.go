@@ -4,9 +4,17 @@ import "net/http"
 
 // AppError định nghĩa cấu trúc lỗi chuẩn của toàn hệ thống
 type AppError struct {
-	HTTPCode int    `json:"-"`        // Không trả ra JSON, chỉ dùng để set HTTP Status Code
-	Code     string `json:"err_code"` // Mã lỗi cho Frontend (VD: ERR_USER_404)
-	Message  string `json:"message"`  // Thông báo lỗi cho người dùng đọc
+	HTTPCode int          `json:"-"`                 // Không trả ra JSON, chỉ dùng để set HTTP Status Code
+	Code     string       `json:"err_code"`          // Mã lỗi cho Frontend (VD: ERR_USER_404)
+	Message  string       `json:"message"`           // Thông báo lỗi cho người dùng đọc
+	Details  []FieldError `json:"details,omitempty"` // Lý do chi tiết theo từng field (nếu có)
+}
+
+// FieldError mô tả một lý do lỗi gắn với field cụ thể để Frontend hiển thị ngay dưới ô nhập
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // Hàm Error() giúp AppError thoả mãn interface error mặc định của Golang
@@ -14,6 +22,13 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// WithDetails trả về bản sao của lỗi kèm chi tiết (không sửa lỗi định nghĩa sẵn dùng chung)
+func (e *AppError) WithDetails(details ...FieldError) *AppError {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
 // New khởi tạo một lỗi mới
 func New(httpCode int, code, message string) *AppError {
 	return &AppError{
@@ -29,20 +44,111 @@ func New(httpCode int, code, message string) *AppError {
 
 var (
 	// Lỗi hệ thống & Validate
-	ErrInvalidRequest  = New(http.StatusBadRequest, "ERR_BAD_REQUEST", "Dữ liệu yêu cầu không hợp lệ")
-	ErrUnauthorized    = New(http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Không có quyền truy cập hoặc phiên đăng nhập hết hạn")
-	ErrForbidden       = New(http.StatusForbidden, "ERR_FORBIDDEN", "Bạn không có quyền thực hiện hành động này")
-	ErrInternalServer  = New(http.StatusInternalServerError, "ERR_INTERNAL_SERVER", "Lỗi hệ thống, vui lòng thử lại sau")
-	ErrTooManyRequests = New(http.StatusTooManyRequests, "ERR_TOO_MANY_REQUESTS", "Bạn đã gửi quá nhiều yêu cầu. Vui lòng thử lại sau")
+	ErrInvalidRequest   = New(http.StatusBadRequest, "ERR_BAD_REQUEST", "Dữ liệu yêu cầu không hợp lệ")
+	ErrUnauthorized     = New(http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Không có quyền truy cập hoặc phiên đăng nhập hết hạn")
+	ErrForbidden        = New(http.StatusForbidden, "ERR_FORBIDDEN", "Bạn không có quyền thực hiện hành động này")
+	ErrInternalServer   = New(http.StatusInternalServerError, "ERR_INTERNAL_SERVER", "Lỗi hệ thống, vui lòng thử lại sau")
+	ErrTooManyRequests  = New(http.StatusTooManyRequests, "ERR_TOO_MANY_REQUESTS", "Bạn đã gửi quá nhiều yêu cầu. Vui lòng thử lại sau")
+	ErrInvalidCSRFToken = New(http.StatusForbidden, "ERR_INVALID_CSRF_TOKEN", "Thiếu hoặc sai CSRF token (header X-CSRF-Token)")
 
 	// Lỗi liên quan đến User & Auth
-	ErrUserNotFound       = New(http.StatusNotFound, "ERR_USER_NOT_FOUND", "Không tìm thấy người dùng")
-	ErrEmailExists        = New(http.StatusConflict, "ERR_EMAIL_EXISTS", "Email đã được sử dụng")
-	ErrInvalidCredentials = New(http.StatusUnauthorized, "ERR_INVALID_CREDENTIALS", "Sai email hoặc mật khẩu")
-	ErrWrongPassword      = New(http.StatusBadRequest, "ERR_WRONG_PASSWORD", "Mật khẩu cũ không chính xác")
-	ErrInvalidOTP         = New(http.StatusBadRequest, "ERR_INVALID_OTP", "Mã OTP không chính xác")
-	ErrOTPExpired         = New(http.StatusBadRequest, "ERR_OTP_EXPIRED", "Mã OTP đã hết hạn")
-	ErrCannotDeleteSelf   = New(http.StatusForbidden, "ERR_CANNOT_DELETE_SELF", "Hành động nguy hiểm: Không thể tự xoá chính mình")
+	ErrUserNotFound        = New(http.StatusNotFound, "ERR_USER_NOT_FOUND", "Không tìm thấy người dùng")
+	ErrEmailExists         = New(http.StatusConflict, "ERR_EMAIL_EXISTS", "Email đã được sử dụng")
+	ErrInvalidCredentials  = New(http.StatusUnauthorized, "ERR_INVALID_CREDENTIALS", "Sai email hoặc mật khẩu")
+	ErrWeakPassword        = New(http.StatusBadRequest, "ERR_WEAK_PASSWORD", "Mật khẩu không đáp ứng chính sách bảo mật")
+	ErrWrongPassword       = New(http.StatusBadRequest, "ERR_WRONG_PASSWORD", "Mật khẩu cũ không chính xác")
+	ErrInvalidOTP          = New(http.StatusBadRequest, "ERR_INVALID_OTP", "Mã OTP không chính xác")
+	ErrOTPExpired          = New(http.StatusBadRequest, "ERR_OTP_EXPIRED", "Mã OTP đã hết hạn")
+	ErrOTPAttemptsExceeded = New(http.StatusBadRequest, "ERR_OTP_ATTEMPTS_EXCEEDED", "Mã OTP đã bị huỷ do nhập sai quá nhiều lần, vui lòng yêu cầu mã mới")
+	ErrCannotDeleteSelf    = New(http.StatusForbidden, "ERR_CANNOT_DELETE_SELF", "Hành động nguy hiểm: Không thể tự xoá chính mình")
+	ErrAccountLocked       = New(http.StatusLocked, "ERR_ACCOUNT_LOCKED", "Tài khoản tạm thời bị khoá do đăng nhập sai nhiều lần. Vui lòng thử lại sau hoặc liên hệ quản trị viên")
+	ErrEmailNotVerified    = New(http.StatusForbidden, "ERR_EMAIL_NOT_VERIFIED", "Vui lòng xác thực email trước khi tiếp tục")
+	ErrInvalidMagicLink    = New(http.StatusBadRequest, "ERR_INVALID_MAGIC_LINK", "Link đăng nhập không hợp lệ, đã hết hạn hoặc đã được sử dụng")
+	ErrInvalidVerifyToken  = New(http.StatusBadRequest, "ERR_INVALID_VERIFY_TOKEN", "Link xác thực email không hợp lệ hoặc đã hết hạn")
+
+	// Lỗi liên quan đến Đình chỉ tài khoản
+	ErrAccountSuspended     = New(http.StatusForbidden, "ERR_ACCOUNT_SUSPENDED", "Tài khoản đang bị đình chỉ, vui lòng liên hệ quản trị viên")
+	ErrCannotSuspendSelf    = New(http.StatusForbidden, "ERR_CANNOT_SUSPEND_SELF", "Không thể tự đình chỉ chính mình")
+	ErrInvalidSuspensionEnd = New(http.StatusBadRequest, "ERR_INVALID_SUSPENSION_END", "Thời điểm gỡ đình chỉ phải ở tương lai")
+	ErrUserNotSuspended     = New(http.StatusConflict, "ERR_USER_NOT_SUSPENDED", "Tài khoản không bị đình chỉ")
+
+	// Lỗi liên quan đến Đổi email
+	ErrSameEmail               = New(http.StatusBadRequest, "ERR_SAME_EMAIL", "Email mới trùng với email hiện tại")
+	ErrEmailRecentlyChanged    = New(http.StatusConflict, "ERR_EMAIL_RECENTLY_CHANGED", "Email vừa được đổi gần đây, vui lòng thử lại sau")
+	ErrInvalidEmailChangeToken = New(http.StatusBadRequest, "ERR_INVALID_EMAIL_CHANGE_TOKEN", "Link đổi email không hợp lệ, đã hết hạn hoặc đã được thay bằng yêu cầu mới hơn")
+
+	// Lỗi liên quan đến Số điện thoại
+	ErrInvalidPhone         = New(http.StatusBadRequest, "ERR_INVALID_PHONE", "Số điện thoại không hợp lệ, vui lòng nhập kèm mã quốc gia (VD: +84912345678)")
+	ErrPhoneRequired        = New(http.StatusBadRequest, "ERR_PHONE_REQUIRED", "Vui lòng cập nhật số điện thoại trước khi xác thực")
+	ErrPhoneAlreadyVerified = New(http.StatusConflict, "ERR_PHONE_ALREADY_VERIFIED", "Số điện thoại đã được xác thực")
+	ErrPhoneExists          = New(http.StatusConflict, "ERR_PHONE_EXISTS", "Số điện thoại đã được xác thực bởi tài khoản khác")
+
+	// Lỗi liên quan đến Lời mời (Invitation)
+	ErrInvitationExists        = New(http.StatusConflict, "ERR_INVITATION_EXISTS", "Email này đang có lời mời chờ chấp nhận")
+	ErrInvitationNotFound      = New(http.StatusNotFound, "ERR_INVITATION_NOT_FOUND", "Không tìm thấy lời mời đang chờ")
+	ErrInvalidInvitation       = New(http.StatusBadRequest, "ERR_INVALID_INVITATION", "Link mời không hợp lệ hoặc đã hết hạn")
+	ErrInvalidInvitationExpiry = New(http.StatusBadRequest, "ERR_INVALID_INVITATION_EXPIRY", "Thời hạn lời mời phải từ 1 đến 30 ngày")
+
+	// Lỗi liên quan đến Role & Permission
+	ErrInvalidRole        = New(http.StatusBadRequest, "ERR_INVALID_ROLE", "Quyền không hợp lệ")
+	ErrRoleNotFound       = New(http.StatusNotFound, "ERR_ROLE_NOT_FOUND", "Không tìm thấy role")
+	ErrRoleExists         = New(http.StatusConflict, "ERR_ROLE_EXISTS", "Tên role đã tồn tại")
+	ErrRoleInUse          = New(http.StatusConflict, "ERR_ROLE_IN_USE", "Role đang được gán cho user, hãy chuyển các user sang role khác trước khi xoá")
+	ErrSystemRole         = New(http.StatusForbidden, "ERR_SYSTEM_ROLE", "Không thể thay đổi role dựng sẵn của hệ thống")
+	ErrPermissionsChanged = New(http.StatusUnauthorized, "ERR_PERMISSIONS_CHANGED", "Quyền của bạn đã thay đổi, vui lòng làm mới token")
+	ErrCannotEditOwnRole  = New(http.StatusForbidden, "ERR_CANNOT_EDIT_OWN_ROLE", "Không thể tự sửa quyền của role mình đang giữ")
+
+	// Lỗi liên quan đến Giả lập user (Impersonation)
+	ErrCannotImpersonateSelf   = New(http.StatusBadRequest, "ERR_CANNOT_IMPERSONATE_SELF", "Không thể giả lập chính mình")
+	ErrImpersonationNotAllowed = New(http.StatusForbidden, "ERR_IMPERSONATION_NOT_ALLOWED", "Không thể thực hiện thao tác này khi đang giả lập người dùng khác")
+	ErrImpersonationEnded      = New(http.StatusUnauthorized, "ERR_IMPERSONATION_ENDED", "Phiên giả lập đã kết thúc hoặc bạn không còn quyền giả lập")
+
+	// Lỗi liên quan đến Tổ chức (Organization / Tenant)
+	ErrOrganizationNotFound = New(http.StatusNotFound, "ERR_ORGANIZATION_NOT_FOUND", "Không tìm thấy tổ chức hoặc bạn không phải thành viên")
+	ErrNoActiveOrganization = New(http.StatusBadRequest, "ERR_NO_ACTIVE_ORGANIZATION", "Vui lòng chọn tổ chức đang làm việc trước")
+	ErrOrgMembershipRevoked = New(http.StatusUnauthorized, "ERR_ORG_MEMBERSHIP_REVOKED", "Bạn không còn là thành viên của tổ chức này, vui lòng làm mới token")
+	ErrInvalidOrgRole       = New(http.StatusBadRequest, "ERR_INVALID_ORG_ROLE", "Role trong tổ chức không hợp lệ")
+	ErrMemberExists         = New(http.StatusConflict, "ERR_MEMBER_EXISTS", "User đã là thành viên của tổ chức")
+	ErrMemberNotFound       = New(http.StatusNotFound, "ERR_MEMBER_NOT_FOUND", "Không tìm thấy thành viên")
+	ErrLastOwner            = New(http.StatusConflict, "ERR_LAST_OWNER", "Tổ chức phải còn ít nhất một owner")
+
+	// Lỗi liên quan đến Phiên đăng nhập (Session)
+	ErrSessionNotFound = New(http.StatusNotFound, "ERR_SESSION_NOT_FOUND", "Không tìm thấy phiên đăng nhập")
+	ErrSessionRevoked  = New(http.StatusUnauthorized, "ERR_SESSION_REVOKED", "Phiên đăng nhập đã bị thu hồi hoặc hết hạn")
+	ErrRefreshReused   = New(http.StatusUnauthorized, "ERR_REFRESH_TOKEN_REUSED", "Refresh token đã được sử dụng. Phiên đăng nhập bị thu hồi vì lý do an toàn")
+
+	// Lỗi liên quan đến nội dung Token
+	ErrTokenPayloadInvalid = New(http.StatusUnauthorized, "ERR_PAYLOAD_INVALID", "Payload của Token không hợp lệ")
+
+	// Lỗi liên quan đến đăng nhập bằng tài khoản bên ngoài (OAuth2 / OIDC)
+	ErrOAuthProviderNotFound = New(http.StatusNotFound, "ERR_OAUTH_PROVIDER_NOT_FOUND", "Phương thức đăng nhập không được hỗ trợ")
+	ErrOAuthStateInvalid     = New(http.StatusBadRequest, "ERR_OAUTH_STATE_INVALID", "Phiên đăng nhập đã hết hạn hoặc không hợp lệ, vui lòng thử lại")
+	ErrOAuthFailed           = New(http.StatusBadGateway, "ERR_OAUTH_FAILED", "Không thể xác thực với nhà cung cấp đăng nhập, vui lòng thử lại sau")
+	ErrOAuthEmailNotVerified = New(http.StatusForbidden, "ERR_OAUTH_EMAIL_NOT_VERIFIED", "Tài khoản bên ngoài chưa có email đã xác thực")
+
+	// Lỗi liên quan đến ứng dụng dùng hệ thống làm OAuth2/OIDC provider
+	ErrInvalidOAuthClient  = New(http.StatusBadRequest, "ERR_INVALID_OAUTH_CLIENT", "Thông tin đăng ký ứng dụng không hợp lệ")
+	ErrOAuthClientNotFound = New(http.StatusNotFound, "ERR_OAUTH_CLIENT_NOT_FOUND", "Không tìm thấy ứng dụng")
+
+	// Lỗi liên quan đến Personal Access Token (API Key)
+	ErrTokenNotFound      = New(http.StatusNotFound, "ERR_TOKEN_NOT_FOUND", "Không tìm thấy token")
+	ErrInvalidScope       = New(http.StatusBadRequest, "ERR_INVALID_SCOPE", "Danh sách quyền (scope) của token không hợp lệ")
+	ErrInvalidTokenExpiry = New(http.StatusBadRequest, "ERR_INVALID_TOKEN_EXPIRY", "Thời hạn token phải từ 1 đến 365 ngày")
+	ErrTooManyTokens      = New(http.StatusConflict, "ERR_TOO_MANY_TOKENS", "Bạn đã đạt số lượng token tối đa, hãy thu hồi bớt token cũ")
+	ErrInsufficientScope  = New(http.StatusForbidden, "ERR_INSUFFICIENT_SCOPE", "Token không được cấp quyền cho thao tác này")
+	ErrAPITokenNotAllowed = New(http.StatusForbidden, "ERR_API_TOKEN_NOT_ALLOWED", "Thao tác này yêu cầu đăng nhập trực tiếp, không dùng được API token")
+
+	// Lỗi liên quan đến Xác thực 2 lớp (2FA)
+	ErrTwoFactorAlreadyEnabled = New(http.StatusConflict, "ERR_2FA_ALREADY_ENABLED", "Xác thực 2 lớp đã được bật")
+	ErrTwoFactorNotEnrolled    = New(http.StatusBadRequest, "ERR_2FA_NOT_ENROLLED", "Bạn chưa khởi tạo xác thực 2 lớp")
+	ErrTwoFactorNotEnabled     = New(http.StatusBadRequest, "ERR_2FA_NOT_ENABLED", "Xác thực 2 lớp chưa được bật")
+	ErrInvalidTwoFactorCode    = New(http.StatusUnauthorized, "ERR_2FA_INVALID_CODE", "Mã xác thực 2 lớp không chính xác")
+	ErrTwoFactorCodeRequired   = New(http.StatusUnauthorized, "ERR_2FA_CODE_REQUIRED", "Tài khoản đã bật xác thực 2 lớp, vui lòng nhập mã xác thực")
+	ErrInvalidMFAChallenge     = New(http.StatusUnauthorized, "ERR_MFA_CHALLENGE_INVALID", "Phiên xác thực 2 lớp không hợp lệ hoặc đã hết hạn, vui lòng đăng nhập lại")
+
+	// Lỗi liên quan đến Xác thực lại trước thao tác nhạy cảm
+	ErrReauthenticationRequired   = New(http.StatusForbidden, "ERR_REAUTHENTICATION_REQUIRED", "Thao tác này yêu cầu xác thực lại, vui lòng nhập lại mật khẩu")
+	ErrReauthenticationNotAllowed = New(http.StatusForbidden, "ERR_REAUTHENTICATION_NOT_ALLOWED", "Chỉ phiên đăng nhập trực tiếp mới xác thực lại được")
 
 	// Lỗi Media & Upload
 	ErrUploadFailed    = New(http.StatusInternalServerError, "ERR_UPLOAD_FAILED", "Lỗi trong quá trình xử lý file")
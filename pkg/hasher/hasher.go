@@ -0,0 +1,81 @@
+// Package hasher cung cấp cơ chế băm mật khẩu có thể thay thế (argon2id, bcrypt).
+// Thuật toán và tham số được mã hoá ngay trong chuỗi hash lưu DB, nhờ vậy hệ thống
+// verify được mọi hash cũ và biết khi nào cần băm lại theo cấu hình mới
+package hasher
+
+import (
+	"errors"
+	"strings"
+)
+
+// Các thuật toán được hỗ trợ
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrUnknownHash trả về khi chuỗi hash không thuộc thuật toán nào được hỗ trợ
+var ErrUnknownHash = errors.New("định dạng hash không được hỗ trợ")
+
+// PasswordHasher băm và kiểm tra mật khẩu
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify trả về needsRehash = true khi hash khớp nhưng được tạo bằng thuật toán/tham số cũ
+	Verify(password, encoded string) (match bool, needsRehash bool, err error)
+}
+
+// Config là cấu hình băm mật khẩu (mục "password" trong file config)
+type Config struct {
+	Algorithm string       `mapstructure:"algorithm"`
+	Argon2    Argon2Params `mapstructure:"argon2"`
+	Bcrypt    BcryptParams `mapstructure:"bcrypt"`
+}
+
+// algorithmHasher là một thuật toán cụ thể
+type algorithmHasher interface {
+	PasswordHasher
+	matches(encoded string) bool
+}
+
+// multiHasher băm bằng thuật toán ưu tiên, verify được mọi thuật toán đã đăng ký
+type multiHasher struct {
+	preferred  algorithmHasher
+	algorithms []algorithmHasher
+}
+
+// New khởi tạo hasher theo cấu hình, mặc định argon2id nếu bỏ trống
+func New(cfg Config) (PasswordHasher, error) {
+	argon := newArgon2Hasher(cfg.Argon2)
+	bcryptHasher := newBcryptHasher(cfg.Bcrypt)
+
+	h := &multiHasher{algorithms: []algorithmHasher{argon, bcryptHasher}}
+	switch strings.ToLower(cfg.Algorithm) {
+	case "", AlgorithmArgon2id:
+		h.preferred = argon
+	case AlgorithmBcrypt:
+		h.preferred = bcryptHasher
+	default:
+		return nil, errors.New("thuật toán băm mật khẩu không được hỗ trợ: " + cfg.Algorithm)
+	}
+	return h, nil
+}
+
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *multiHasher) Verify(password, encoded string) (bool, bool, error) {
+	for _, algorithm := range h.algorithms {
+		if !algorithm.matches(encoded) {
+			continue
+		}
+
+		match, needsRehash, err := algorithm.Verify(password, encoded)
+		if err != nil || !match {
+			return false, false, err
+		}
+		// Hash thuộc thuật toán khác thuật toán đang ưu tiên -> luôn cần băm lại
+		return true, needsRehash || algorithm != h.preferred, nil
+	}
+	return false, false, ErrUnknownHash
+}
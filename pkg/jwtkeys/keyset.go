@@ -0,0 +1,253 @@
+// Package jwtkeys quản lý bộ khoá ký/kiểm tra JWT: hỗ trợ HS256 (tương thích ngược),
+// RS256, ES256, EdDSA, xoay vòng khoá qua header "kid" và xuất JWKS cho service khác
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Các thuật toán được hỗ trợ
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// legacyKeyID là kid nội bộ của secret HS256, dùng cho token cũ không có header "kid"
+const legacyKeyID = "hs256"
+
+// KeyConfig mô tả một khoá trong file config (jwt.keys)
+type KeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyFile string `mapstructure:"private_key_file"` // Bỏ trống => khoá chỉ dùng để verify (đã nghỉ hưu)
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+// Key là một khoá đã được nạp vào bộ nhớ
+type Key struct {
+	ID        string
+	Algorithm string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil nếu khoá chỉ dùng để verify
+	verifyKey interface{}
+}
+
+// KeySet gồm đúng 1 khoá đang dùng để ký và nhiều khoá được chấp nhận khi verify
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+	algs   []string
+}
+
+// Load dựng KeySet từ config. Không khai báo keys => ký HS256 bằng secret như trước đây.
+// Nếu có secret, nó vẫn được giữ lại để verify các token cũ chưa có "kid" trong giai đoạn chuyển đổi
+func Load(secret string, signingKeyID string, configs []KeyConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+
+	if secret != "" {
+		ks.add(&Key{
+			ID:        legacyKeyID,
+			Algorithm: AlgHS256,
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(secret),
+			verifyKey: []byte(secret),
+		})
+	}
+
+	for _, cfg := range configs {
+		key, err := loadKey(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("khoá %q: %w", cfg.ID, err)
+		}
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("trùng kid %q", key.ID)
+		}
+		ks.add(key)
+	}
+
+	if len(configs) == 0 {
+		signingKeyID = legacyKeyID
+	}
+
+	active, ok := ks.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("không tìm thấy khoá ký %q", signingKeyID)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("khoá ký %q thiếu private key", signingKeyID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+func (ks *KeySet) add(key *Key) {
+	ks.keys[key.ID] = key
+	for _, alg := range ks.algs {
+		if alg == key.Algorithm {
+			return
+		}
+	}
+	ks.algs = append(ks.algs, key.Algorithm)
+}
+
+// Sign ký claims bằng khoá đang hoạt động và gắn "kid" vào header
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.signKey)
+}
+
+// SigningAlgorithm trả về thuật toán của khoá đang ký (dùng cho metadata OIDC discovery)
+func (ks *KeySet) SigningAlgorithm() string {
+	return ks.active.Algorithm
+}
+
+// Parse kiểm tra chữ ký theo "kid" và hạn dùng, trả về claims
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = legacyKeyID
+		}
+
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("kid %q không hợp lệ", kid)
+		}
+		// Chặn tấn công đổi thuật toán (VD: ký HS256 bằng public key RSA)
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("thuật toán không khớp với khoá")
+		}
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods(ks.algs))
+	if err != nil || !token.Valid {
+		return nil, errors.New("token không hợp lệ")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("claims không hợp lệ")
+	}
+	return claims, nil
+}
+
+func loadKey(cfg KeyConfig) (*Key, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("thiếu id")
+	}
+
+	key := &Key{ID: cfg.ID, Algorithm: cfg.Algorithm}
+	switch cfg.Algorithm {
+	case AlgRS256:
+		key.method = jwt.SigningMethodRS256
+	case AlgES256:
+		key.method = jwt.SigningMethodES256
+	case AlgEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("thuật toán %q không được hỗ trợ", cfg.Algorithm)
+	}
+
+	switch {
+	case cfg.PrivateKeyFile != "":
+		private, err := readPrivateKey(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = private
+		key.verifyKey = private.Public()
+	case cfg.PublicKeyFile != "":
+		public, err := readPublicKey(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.verifyKey = public
+	default:
+		return nil, errors.New("cần private_key_file hoặc public_key_file")
+	}
+
+	if err := checkKeyType(cfg.Algorithm, key.verifyKey); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// checkKeyType đảm bảo loại khoá khớp với thuật toán khai báo
+func checkKeyType(alg string, public crypto.PublicKey) error {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if alg == AlgRS256 {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if alg == AlgES256 && pub.Curve == elliptic.P256() {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if alg == AlgEdDSA {
+			return nil
+		}
+	}
+	return fmt.Errorf("loại khoá không phù hợp với thuật toán %s", alg)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("file %s không phải định dạng PEM", path)
+	}
+	return block, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("không đọc được private key trong %s", path)
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("không đọc được public key trong %s", path)
+}
@@ -0,0 +1,98 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Endpoint mặc định của GitHub, có thể ghi đè trong config (GitHub Enterprise hoặc mock server)
+const (
+	githubAuthorizationURL = "https://github.com/login/oauth/authorize"
+	githubTokenURL         = "https://github.com/login/oauth/access_token"
+	githubAPIURL           = "https://api.github.com"
+)
+
+// githubProvider dùng OAuth2 thuần của GitHub (không có id_token), thông tin user lấy từ REST API
+type githubProvider struct {
+	cfg         ProviderConfig
+	redirectURL string
+	apiURL      string
+}
+
+func newGitHubProvider(cfg ProviderConfig, redirectURL string) *githubProvider {
+	if cfg.AuthorizationURL == "" {
+		cfg.AuthorizationURL = githubAuthorizationURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = githubTokenURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"read:user", "user:email"}
+	}
+
+	apiURL := githubAPIURL
+	if cfg.UserInfoURL != "" {
+		apiURL = strings.TrimSuffix(strings.TrimRight(cfg.UserInfoURL, "/"), "/user")
+	}
+	return &githubProvider{cfg: cfg, redirectURL: redirectURL, apiURL: apiURL}
+}
+
+func (p *githubProvider) AuthCodeURL(_ context.Context, req AuthRequest) (string, error) {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {req.State},
+		"code_challenge":        {CodeChallenge(req.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	return appendQuery(p.cfg.AuthorizationURL, params), nil
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	token, err := exchangeCode(ctx, p.cfg, p.cfg.TokenURL, p.redirectURL, code, req.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, p.apiURL+"/user", token.AccessToken, &profile); err != nil || profile.ID == 0 {
+		return nil, fmt.Errorf("%w: không đọc được /user: %v", ErrExchangeFailed, err)
+	}
+
+	identity := &Identity{
+		Subject: strconv.FormatInt(profile.ID, 10), // ID số không đổi, login thì user đổi được
+		Name:    profile.Name,
+		Picture: profile.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = profile.Login
+	}
+
+	// Email công khai trên profile chưa chắc đã xác thực, chỉ tin email primary + verified
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.apiURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, fmt.Errorf("%w: không đọc được /user/emails: %v", ErrExchangeFailed, err)
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,207 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+// discoveryDocument là phần metadata cần dùng trong /.well-known/openid-configuration
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// oidcProvider làm việc với mọi issuer tuân thủ OpenID Connect (Google, Keycloak, mock server...)
+type oidcProvider struct {
+	cfg         ProviderConfig
+	redirectURL string
+
+	mu        sync.Mutex
+	endpoints *discoveryDocument
+}
+
+func newOIDCProvider(cfg ProviderConfig, redirectURL string) *oidcProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaultOIDCScopes
+	}
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	return &oidcProvider{cfg: cfg, redirectURL: redirectURL}
+}
+
+// discover lấy endpoint từ discovery document, endpoint khai báo trong config được ưu tiên.
+// Kết quả chỉ được cache khi thành công để lần sau còn thử lại
+func (p *oidcProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+
+	doc := &discoveryDocument{
+		Issuer:                p.cfg.Issuer,
+		AuthorizationEndpoint: p.cfg.AuthorizationURL,
+		TokenEndpoint:         p.cfg.TokenURL,
+		UserInfoEndpoint:      p.cfg.UserInfoURL,
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		var remote discoveryDocument
+		if err := getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", "", &remote); err != nil {
+			return nil, err
+		}
+		// Chống trường hợp discovery bị giả mạo trỏ sang issuer khác
+		if strings.TrimRight(remote.Issuer, "/") != p.cfg.Issuer {
+			return nil, fmt.Errorf("issuer trong discovery (%s) không khớp cấu hình", remote.Issuer)
+		}
+		if doc.AuthorizationEndpoint == "" {
+			doc.AuthorizationEndpoint = remote.AuthorizationEndpoint
+		}
+		if doc.TokenEndpoint == "" {
+			doc.TokenEndpoint = remote.TokenEndpoint
+		}
+		if doc.UserInfoEndpoint == "" {
+			doc.UserInfoEndpoint = remote.UserInfoEndpoint
+		}
+	}
+
+	p.endpoints = doc
+	return doc, nil
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	endpoints, err := p.discover(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {CodeChallenge(req.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	return appendQuery(endpoints.AuthorizationEndpoint, params), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	endpoints, err := p.discover(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+
+	token, err := exchangeCode(ctx, p.cfg, endpoints.TokenEndpoint, p.redirectURL, code, req.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: thiếu id_token", ErrExchangeFailed)
+	}
+
+	identity, err := p.identityFromIDToken(token.IDToken, req.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+
+	// Một số issuer không đưa email vào id_token, khi đó bổ sung từ userinfo endpoint
+	if identity.Email == "" && endpoints.UserInfoEndpoint != "" {
+		var claims map[string]interface{}
+		if err := getJSON(ctx, endpoints.UserInfoEndpoint, token.AccessToken, &claims); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+		}
+		// OIDC Core 5.3.2: sub của userinfo bắt buộc phải trùng với id_token
+		if sub, _ := claims["sub"].(string); sub != identity.Subject {
+			return nil, fmt.Errorf("%w: sub của userinfo không khớp id_token", ErrExchangeFailed)
+		}
+		fillIdentity(identity, claims)
+	}
+	return identity, nil
+}
+
+// identityFromIDToken kiểm tra các claim bắt buộc của id_token.
+// id_token nhận trực tiếp từ token endpoint qua kênh TLS nên không cần verify chữ ký (OIDC Core 3.1.3.7),
+// nhưng vẫn phải kiểm tra iss, aud, exp và nonce
+func (p *oidcProvider) identityFromIDToken(idToken, nonce string) (*Identity, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+		return nil, err
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimRight(iss, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("iss không hợp lệ: %s", iss)
+	}
+
+	audiences, err := claims.GetAudience()
+	if err != nil || !containsString(audiences, p.cfg.ClientID) {
+		return nil, fmt.Errorf("aud không chứa client_id")
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil || exp.Before(time.Now()) {
+		return nil, fmt.Errorf("id_token đã hết hạn")
+	}
+
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, fmt.Errorf("nonce không khớp")
+	}
+
+	identity := &Identity{}
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("thiếu sub")
+	}
+	fillIdentity(identity, claims)
+	return identity, nil
+}
+
+// fillIdentity đọc các claim chuẩn (email, email_verified, name, picture)
+func fillIdentity(identity *Identity, claims map[string]interface{}) {
+	if email, ok := claims["email"].(string); ok && email != "" {
+		identity.Email = email
+		// Một số issuer trả email_verified dạng chuỗi "true"
+		switch verified := claims["email_verified"].(type) {
+		case bool:
+			identity.EmailVerified = verified
+		case string:
+			identity.EmailVerified = verified == "true"
+		}
+	}
+	if name, ok := claims["name"].(string); ok && identity.Name == "" {
+		identity.Name = name
+	}
+	if picture, ok := claims["picture"].(string); ok && identity.Picture == "" {
+		identity.Picture = picture
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// appendQuery nối tham số vào URL, giữ nguyên query có sẵn của endpoint
+func appendQuery(endpoint string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+	return endpoint + separator + params.Encode()
+}
@@ -0,0 +1,136 @@
+// Package oauth cài đặt phía client của OAuth2 authorization code + PKCE cho đăng nhập
+// bằng tài khoản bên ngoài (Google, GitHub hoặc bất kỳ OpenID Connect issuer nào).
+// Mọi endpoint đều cấu hình được nên có thể chạy với một mock OIDC server ở local
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Các loại provider được hỗ trợ
+const (
+	TypeOIDC   = "oidc"
+	TypeGitHub = "github"
+)
+
+var (
+	// ErrUnknownProvider trả về khi tên provider không có trong cấu hình
+	ErrUnknownProvider = errors.New("provider không tồn tại")
+	// ErrExchangeFailed bao mọi lỗi khi đổi code lấy token hoặc đọc thông tin user từ provider
+	ErrExchangeFailed = errors.New("không lấy được thông tin từ provider")
+)
+
+// ProviderConfig là cấu hình của một provider (mục "oauth.providers.<tên>" trong file config).
+// Với OIDC chỉ cần Issuer, các endpoint còn lại tự lấy từ discovery nếu bỏ trống
+type ProviderConfig struct {
+	Type             string   `mapstructure:"type"`
+	ClientID         string   `mapstructure:"client_id"`
+	ClientSecret     string   `mapstructure:"client_secret"`
+	Issuer           string   `mapstructure:"issuer"`
+	AuthorizationURL string   `mapstructure:"authorization_url"`
+	TokenURL         string   `mapstructure:"token_url"`
+	UserInfoURL      string   `mapstructure:"userinfo_url"`
+	Scopes           []string `mapstructure:"scopes"`
+}
+
+// Config là cấu hình đăng nhập bằng tài khoản bên ngoài (mục "oauth" trong file config)
+type Config struct {
+	// Callback của provider X là RedirectBaseURL + "/X/callback", phải khớp với URL đã đăng ký ở provider
+	RedirectBaseURL string                    `mapstructure:"redirect_base_url"`
+	Providers       map[string]ProviderConfig `mapstructure:"providers"`
+}
+
+// Identity là thông tin định danh user do provider xác nhận
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// AuthRequest chứa các giá trị ngẫu nhiên của một lượt đăng nhập, được tạo ở bước chuyển hướng
+// và phải được giữ lại (phía client) để kiểm tra ở bước callback
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// Provider là một nhà cung cấp định danh bên ngoài
+type Provider interface {
+	// AuthCodeURL trả về URL chuyển hướng user sang trang đăng nhập của provider
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// Exchange đổi authorization code lấy thông tin định danh của user
+	Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error)
+}
+
+// Registry quản lý các provider đã cấu hình theo tên
+type Registry struct {
+	providers map[string]Provider
+}
+
+// httpClient dùng chung, có timeout để provider chậm không giữ request của user mãi
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewRegistry khởi tạo provider từ cấu hình. Discovery của OIDC được thực hiện lười ở lần dùng đầu,
+// nên provider tạm thời không truy cập được cũng không làm app khởi động thất bại
+func NewRegistry(cfg Config) (*Registry, error) {
+	registry := &Registry{providers: make(map[string]Provider)}
+	base := strings.TrimRight(cfg.RedirectBaseURL, "/")
+
+	for name, providerCfg := range cfg.Providers {
+		if providerCfg.ClientID == "" {
+			return nil, errors.New("provider " + name + " thiếu client_id")
+		}
+		redirectURL := base + "/" + name + "/callback"
+
+		switch strings.ToLower(providerCfg.Type) {
+		case "", TypeOIDC:
+			if providerCfg.Issuer == "" {
+				return nil, errors.New("provider " + name + " thiếu issuer")
+			}
+			registry.providers[name] = newOIDCProvider(providerCfg, redirectURL)
+		case TypeGitHub:
+			registry.providers[name] = newGitHubProvider(providerCfg, redirectURL)
+		default:
+			return nil, errors.New("provider " + name + " có type không được hỗ trợ: " + providerCfg.Type)
+		}
+	}
+	return registry, nil
+}
+
+// Get trả về provider theo tên
+func (r *Registry) Get(name string) (Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// NewAuthRequest sinh state, nonce và PKCE code verifier cho một lượt đăng nhập
+func NewAuthRequest() (AuthRequest, error) {
+	var values [3]string
+	for i := range values {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return AuthRequest{}, err
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(buf)
+	}
+	return AuthRequest{State: values[0], Nonce: values[1], CodeVerifier: values[2]}, nil
+}
+
+// CodeChallenge tính PKCE code_challenge theo phương thức S256 (RFC 7636)
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -10,10 +10,11 @@ import (
 
 // Chuẩn hoá cấu trúc trả về
 type Response struct {
-	Code    int         `json:"code"`
-	ErrCode string      `json:"err_code,omitempty"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"` // Nếu null sẽ không hiển thị json field này
+	Code    int                       `json:"code"`
+	ErrCode string                    `json:"err_code,omitempty"`
+	Message string                    `json:"message"`
+	Data    interface{}               `json:"data,omitempty"`    // Nếu null sẽ không hiển thị json field này
+	Details []custom_error.FieldError `json:"details,omitempty"` // Lý do lỗi chi tiết theo từng field
 }
 
 // Hàm Success chuẩn hoá
@@ -32,6 +33,7 @@ func Error(c *gin.Context, err error) {
 			Code:    appErr.HTTPCode,
 			ErrCode: appErr.Code,
 			Message: appErr.Message,
+			Details: appErr.Details,
 		})
 		return
 	}
@@ -0,0 +1,64 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPConfig là cấu hình gateway SMS dạng HTTP API
+type HTTPConfig struct {
+	URL    string `mapstructure:"url"`     // Endpoint nhận POST JSON {"from", "to", "message"}
+	APIKey string `mapstructure:"api_key"` // Gửi kèm header "Authorization: Bearer <api_key>"
+	From   string `mapstructure:"from"`    // Brandname hoặc số gửi đã đăng ký với nhà cung cấp
+}
+
+type httpSender struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+func newHTTPSender(cfg HTTPConfig) *httpSender {
+	return &httpSender{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type httpMessage struct {
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+func (s *httpSender) Send(to string, message string) error {
+	payload, err := json.Marshal(httpMessage{From: s.cfg.From, To: to, Message: message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.APIKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Đọc bỏ phần thân (có giới hạn) để tái sử dụng kết nối
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s trả về HTTP %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
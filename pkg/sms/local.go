@@ -0,0 +1,40 @@
+package sms
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go-core-api/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// consoleSender không gửi tin thật mà in nội dung ra log (chỉ dùng khi phát triển, log chứa cả mã OTP)
+type consoleSender struct{}
+
+func (s *consoleSender) Send(to string, message string) error {
+	logger.Info("📱 SMS (console)", zap.String("to", to), zap.String("message", message))
+	return nil
+}
+
+// fileSender ghi thêm mỗi tin nhắn thành một dòng vào file, giúp test tự động đọc lại mã OTP
+type fileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSender) Send(to string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, message)
+	return err
+}
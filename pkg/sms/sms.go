@@ -0,0 +1,55 @@
+// Package sms gửi tin nhắn SMS qua provider có thể thay thế (console, file, HTTP gateway).
+// Số điện thoại truyền vào luôn ở dạng E.164 (VD: +84912345678)
+package sms
+
+import (
+	"errors"
+	"strings"
+
+	"go-core-api/pkg/logger"
+)
+
+// Các provider được hỗ trợ
+const (
+	ProviderConsole = "console" // Ghi tin nhắn ra log, dùng khi phát triển
+	ProviderFile    = "file"    // Ghi tin nhắn vào file, dùng cho môi trường test
+	ProviderHTTP    = "http"    // Gửi qua HTTP API của nhà cung cấp SMS
+)
+
+// Sender interface giúp dễ dàng đổi nhà cung cấp hoặc Mock test sau này (tương tự mailer.Mailer)
+type Sender interface {
+	Send(to string, message string) error
+}
+
+// Config là cấu hình gửi SMS (mục "sms" trong file config)
+type Config struct {
+	Provider string `mapstructure:"provider"`
+	// Mã quốc gia dùng để chuẩn hoá số nhập không kèm "+" (VD: "84" => 0912345678 thành +84912345678)
+	DefaultCountryCode string     `mapstructure:"default_country_code"`
+	FilePath           string     `mapstructure:"file_path"`
+	HTTP               HTTPConfig `mapstructure:"http"`
+}
+
+// New khởi tạo Sender theo cấu hình. Provider phải được chọn rõ ràng: bỏ trống thì báo lỗi
+// thay vì lặng lẽ dùng console, vì console ghi cả mã OTP ra log
+func New(cfg Config) (Sender, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, errors.New("sms.provider không được bỏ trống (console | file | http)")
+	case ProviderConsole:
+		logger.Warn("SMS provider console ghi mã OTP ra log, chỉ dùng khi phát triển")
+		return &consoleSender{}, nil
+	case ProviderFile:
+		if cfg.FilePath == "" {
+			return nil, errors.New("sms.file_path không được bỏ trống khi dùng provider file")
+		}
+		return &fileSender{path: cfg.FilePath}, nil
+	case ProviderHTTP:
+		if cfg.HTTP.URL == "" {
+			return nil, errors.New("sms.http.url không được bỏ trống khi dùng provider http")
+		}
+		return newHTTPSender(cfg.HTTP), nil
+	default:
+		return nil, errors.New("provider SMS không được hỗ trợ: " + cfg.Provider)
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import "strings"
+
+const (
+	// PersonalTokenPrefix giúp nhận diện token (và giúp công cụ quét secret phát hiện khi bị lộ lên Git)
+	PersonalTokenPrefix = "pat_"
+	apiTokenAlphabet    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// GeneratePersonalToken sinh token dạng "pat_<prefix>_<secret>", trả về cả prefix để lưu DB tra cứu
+func GeneratePersonalToken() (token string, prefix string, err error) {
+	prefix, err = GenerateRandomString(8, apiTokenAlphabet)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := GenerateRandomString(32, apiTokenAlphabet)
+	if err != nil {
+		return "", "", err
+	}
+	return PersonalTokenPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// ParsePersonalToken tách prefix từ token người dùng gửi lên
+func ParsePersonalToken(token string) (prefix string, ok bool) {
+	if !strings.HasPrefix(token, PersonalTokenPrefix) {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(token, PersonalTokenPrefix), "_")
+	if len(parts) != 2 || len(parts[0]) != 8 || len(parts[1]) != 32 {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// IsPersonalToken kiểm tra nhanh chuỗi có phải PAT không (để chọn luồng xác thực)
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
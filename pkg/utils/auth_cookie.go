@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"go-core-api/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cookie dùng cho chế độ xác thực bằng cookie (auth.cookie.enabled) của trình duyệt
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFCookie         = "csrf_token" // Không HttpOnly: SPA đọc giá trị và gửi lại qua header CSRFHeader
+	CSRFHeader         = "X-CSRF-Token"
+
+	// Refresh Token chỉ được trình duyệt gửi kèm request làm mới token
+	RefreshCookiePath = "/api/v1/auth/refresh-token"
+)
+
+// CookieAuthEnabled cho biết login/refresh trả token qua cookie HttpOnly thay vì JSON body
+func CookieAuthEnabled() bool {
+	return config.AppConfig.Auth.Cookie.Enabled
+}
+
+// SetAuthCookies ghi Access/Refresh Token vào cookie HttpOnly và sinh CSRF token mới (double-submit).
+// Trả về CSRF token để client dùng ngay mà không cần đọc cookie
+func SetAuthCookies(c *gin.Context, accessToken, refreshToken string) (string, error) {
+	csrfToken, err := GenerateRandomString(32, apiTokenAlphabet)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := config.AppConfig
+	accessMaxAge := cfg.JWT.AccessExpiration * 60
+	refreshMaxAge := cfg.JWT.RefreshExpiration * 24 * 60 * 60
+	domain := cfg.Auth.Cookie.Domain
+
+	c.SetSameSite(cookieSameSite())
+	c.SetCookie(AccessTokenCookie, accessToken, accessMaxAge, "/", domain, true, true)
+	c.SetCookie(RefreshTokenCookie, refreshToken, refreshMaxAge, RefreshCookiePath, domain, true, true)
+	c.SetCookie(CSRFCookie, csrfToken, refreshMaxAge, "/", domain, true, false)
+	return csrfToken, nil
+}
+
+// ClearAuthCookies xoá toàn bộ cookie đăng nhập (đăng xuất)
+func ClearAuthCookies(c *gin.Context) {
+	domain := config.AppConfig.Auth.Cookie.Domain
+
+	c.SetSameSite(cookieSameSite())
+	c.SetCookie(AccessTokenCookie, "", -1, "/", domain, true, true)
+	c.SetCookie(RefreshTokenCookie, "", -1, RefreshCookiePath, domain, true, true)
+	c.SetCookie(CSRFCookie, "", -1, "/", domain, true, false)
+}
+
+// ValidCSRF so khớp header X-CSRF-Token với cookie csrf_token.
+// Trang web khác gửi request kèm cookie được nhưng không đọc được giá trị cookie để đặt vào header
+func ValidCSRF(c *gin.Context) bool {
+	return ValidCSRFValue(c, c.GetHeader(CSRFHeader))
+}
+
+// ValidCSRFValue so khớp giá trị CSRF gửi kèm request (header hoặc field của form HTML) với cookie csrf_token
+func ValidCSRFValue(c *gin.Context, value string) bool {
+	cookie, err := c.Cookie(CSRFCookie)
+	if err != nil || cookie == "" || value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(value)) == 1
+}
+
+// IsSafeMethod: các method chỉ đọc dữ liệu, không cần CSRF token
+func IsSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func cookieSameSite() http.SameSite {
+	switch strings.ToLower(config.AppConfig.Auth.Cookie.SameSite) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
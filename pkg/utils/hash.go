@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken băm SHA-256 các chuỗi bí mật có entropy cao (recovery code, API key...)
+// trước khi lưu DB. Không dùng cho mật khẩu người dùng (đã có bcrypt)
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRandomString sinh chuỗi ngẫu nhiên an toàn từ bảng ký tự alphabet (tối đa 256 ký tự).
+// Dùng rejection sampling để mọi ký tự có xác suất xuất hiện như nhau (không bị lệch do phép chia dư)
+func GenerateRandomString(length int, alphabet string) (string, error) {
+	limit := 256 - 256%len(alphabet)
+	result := make([]byte, 0, length)
+	buf := make([]byte, length)
+
+	for len(result) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, v := range buf {
+			if int(v) >= limit {
+				continue // Bỏ các byte rơi vào vùng gây lệch phân phối
+			}
+			result = append(result, alphabet[int(v)%len(alphabet)])
+			if len(result) == length {
+				break
+			}
+		}
+	}
+	return string(result), nil
+}
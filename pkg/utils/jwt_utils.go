@@ -20,3 +20,49 @@ func GetUserIDFromContext(c *gin.Context) (uint, error) {
 
 	return userIDVal, nil
 }
+
+// GetSessionIDFromContext trích xuất session_id của phiên hiện tại (do RequireAuth truyền vào)
+func GetSessionIDFromContext(c *gin.Context) (uint, error) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return 0, custom_error.ErrUnauthorized
+	}
+
+	sessionIDVal, ok := sessionID.(uint)
+	if !ok {
+		return 0, custom_error.ErrUnauthorized
+	}
+
+	return sessionIDVal, nil
+}
+
+// GetOrganizationIDFromContext trích xuất tổ chức đang làm việc (claim org_id, do RequireAuth truyền vào)
+func GetOrganizationIDFromContext(c *gin.Context) (uint, error) {
+	organizationID, exists := c.Get("org_id")
+	if !exists {
+		return 0, custom_error.ErrNoActiveOrganization
+	}
+
+	organizationIDVal, ok := organizationID.(uint)
+	if !ok {
+		return 0, custom_error.ErrNoActiveOrganization
+	}
+
+	return organizationIDVal, nil
+}
+
+// GetActorIDFromContext trích xuất người thực sự gửi request (do RequireAuth truyền vào).
+// Bằng user_id, trừ khi admin đang giả lập user khác
+func GetActorIDFromContext(c *gin.Context) (uint, error) {
+	actorID, exists := c.Get("actor_id")
+	if !exists {
+		return 0, custom_error.ErrUnauthorized
+	}
+
+	actorIDVal, ok := actorID.(uint)
+	if !ok {
+		return 0, custom_error.ErrUnauthorized
+	}
+
+	return actorIDVal, nil
+}
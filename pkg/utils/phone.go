@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"strings"
+
+	"go-core-api/pkg/custom_error"
+)
+
+// NormalizePhone chuẩn hoá số điện thoại về dạng E.164 (+<mã quốc gia><số>, tối đa 15 chữ số).
+// Chấp nhận khoảng trắng, dấu chấm, gạch ngang, ngoặc đơn và tiền tố quốc tế "00".
+// Số bắt đầu bằng "0" (số nội địa) được ghép với defaultCountryCode, bỏ trống thì bắt buộc nhập kèm "+"
+func NormalizePhone(raw, defaultCountryCode string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		cleaned = cleaned[1:]
+	case strings.HasPrefix(cleaned, "00"):
+		cleaned = cleaned[2:]
+	case strings.HasPrefix(cleaned, "0") && defaultCountryCode != "":
+		cleaned = strings.TrimPrefix(defaultCountryCode, "+") + cleaned[1:]
+	default:
+		return "", custom_error.ErrInvalidPhone
+	}
+
+	// E.164: mã quốc gia không bắt đầu bằng 0, tổng cộng 8-15 chữ số
+	if len(cleaned) < 8 || len(cleaned) > 15 || cleaned[0] == '0' {
+		return "", custom_error.ErrInvalidPhone
+	}
+	for _, r := range cleaned {
+		if r < '0' || r > '9' {
+			return "", custom_error.ErrInvalidPhone
+		}
+	}
+	return "+" + cleaned, nil
+}